@@ -0,0 +1,137 @@
+package transmission
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long the daemon counts a torrent as recently active, and remembers
+// removed ids for, less a margin for the time a request takes to arrive
+// @link: https://github.com/transmission/transmission/blob/main/libtransmission/rpcimpl.cc
+const recentlyActiveWindow = 50 * time.Second
+
+// a local copy of the daemons torrents which, after one full fetch, is kept
+// current by asking only for the recently active torrents and the ids removed
+// since, so polling large instances stays cheap; the fields default to those
+// requested by Get, and the hash is always added since numeric ids change
+// when the daemon restarts
+//
+// everything is fetched again when the previous sync is older than the
+// daemons window of recent activity, or when the session token changes
+// since that means the daemon restarted
+type Cache struct {
+	sync.RWMutex
+	Transmission *Transmission
+	Fields       []Field
+
+	syncing  sync.Mutex
+	synced   time.Time
+	torrents map[string]Torrent
+	hashes   map[int]string
+}
+
+// brings the cache up to date, leaving the previous snapshot in place when
+// the request fails
+func (self *Cache) Sync() error {
+	return self.SyncContext(context.Background())
+}
+
+func (self *Cache) SyncContext(ctx context.Context) error {
+	self.syncing.Lock()
+	defer self.syncing.Unlock()
+
+	fields := self.Fields
+	if len(fields) == 0 {
+		fields = []Field{FieldFinished}
+	}
+	fields = append(fields[:len(fields):len(fields)], FieldHashString)
+
+	self.RLock()
+	full := self.torrents == nil || time.Since(self.synced) >= recentlyActiveWindow
+	self.RUnlock()
+
+	for {
+		var ids []TorrentID
+		if !full {
+			ids = []TorrentID{RecentlyActive}
+		}
+		started := time.Now()
+		token := self.token()
+		args, err := self.Transmission.torrentGet(ctx, ids, fields...)
+		if err != nil {
+			return err
+		} else if !full && self.token() != token {
+			full = true
+			continue
+		}
+		self.apply(args, full, started)
+		return nil
+	}
+}
+
+// the session token, which the daemon replaces when it restarts
+func (self *Cache) token() string {
+	self.Transmission.RLock()
+	defer self.Transmission.RUnlock()
+	return self.Transmission.Token
+}
+
+// replaces or updates the cached torrents with the results of a sync
+func (self *Cache) apply(args arguments, full bool, started time.Time) {
+	self.Lock()
+	defer self.Unlock()
+	if full {
+		self.torrents = make(map[string]Torrent, len(args.Torrents))
+		self.hashes = make(map[int]string, len(args.Torrents))
+	}
+	for _, t := range args.Torrents {
+		self.torrents[t.HashString] = t
+		self.hashes[t.Id] = t.HashString
+	}
+	for _, id := range args.Removed {
+		delete(self.torrents, self.hashes[id])
+		delete(self.hashes, id)
+	}
+	self.synced = started
+}
+
+// discards the cached torrents so the next sync fetches everything, which
+// is needed after changing the fields
+func (self *Cache) Reset() {
+	self.syncing.Lock()
+	defer self.syncing.Unlock()
+	self.Lock()
+	defer self.Unlock()
+	self.torrents, self.hashes = nil, nil
+}
+
+// a copy of the cached torrents ordered by id
+func (self *Cache) Snapshot() []Torrent {
+	self.RLock()
+	defer self.RUnlock()
+	torrents := make([]Torrent, 0, len(self.torrents))
+	for _, t := range self.torrents {
+		torrents = append(torrents, t)
+	}
+	sort.Slice(torrents, func(i, j int) bool { return torrents[i].Id < torrents[j].Id })
+	return torrents
+}
+
+// finds a cached torrent by its hash, or by the numeric id it had at the
+// last sync
+func (self *Cache) Torrent(id TorrentID) (Torrent, bool) {
+	self.RLock()
+	defer self.RUnlock()
+	var hash string
+	switch t := id.(type) {
+	case Hash:
+		hash = strings.ToLower(string(t))
+	case ID:
+		hash = self.hashes[int(t)]
+	}
+	torrent, ok := self.torrents[hash]
+	return torrent, ok && hash != ""
+}
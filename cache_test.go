@@ -0,0 +1,161 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server which answers the full fetch, then changes
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		// verify request
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		if c.Method != "torrent-get" || !reflect.DeepEqual(c.Arguments.Fields, []string{"id", "name", "percentDone", "hashString"}) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+
+		w.WriteHeader(http.StatusOK)
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			if c.Arguments.Ids != nil {
+				t.Fail()
+			}
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa","name":"a","percentDone":0.5},{"id":2,"hashString":"bb","name":"b","percentDone":1},{"id":3,"hashString":"cc","name":"c","percentDone":1}]}}`))
+		case 2:
+			if c.Arguments.Ids != "recently-active" {
+				t.Fail()
+			}
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa","name":"a","percentDone":0.75},{"id":4,"hashString":"dd","name":"d","percentDone":0}],"removed":[2]}}`))
+		default:
+			w.Write([]byte(`{"result":"failure","arguments":{}}`))
+		}
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	tr := &Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 1}}
+	cache := Cache{Transmission: tr, Fields: []Field{FieldName, FieldPercentDone}}
+	if len(cache.Snapshot()) != 0 {
+		t.FailNow()
+	}
+
+	// verify the full fetch
+	if err := cache.Sync(); err != nil || len(cache.Snapshot()) != 3 {
+		t.Logf("error (%v) or unexpected snapshot: %+v", err, cache.Snapshot())
+		t.FailNow()
+	}
+
+	// verify changes, additions and removals are applied
+	if err := cache.Sync(); err != nil {
+		t.FailNow()
+	}
+	names := []string{}
+	for _, t := range cache.Snapshot() {
+		names = append(names, t.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "c", "d"}) {
+		t.Logf("unexpected snapshot: %+v", cache.Snapshot())
+		t.FailNow()
+	}
+	if a, ok := cache.Torrent(ID(1)); !ok || a.PercentDone != 0.75 {
+		t.FailNow()
+	}
+	if d, ok := cache.Torrent(Hash("DD")); !ok || d.Name != "d" {
+		t.FailNow()
+	}
+	if _, ok := cache.Torrent(ID(2)); ok {
+		t.FailNow()
+	}
+
+	// verify a failed sync keeps the previous snapshot
+	if err := cache.Sync(); err == nil || len(cache.Snapshot()) != 3 {
+		t.FailNow()
+	}
+
+	// verify a reset empties the cache
+	cache.Reset()
+	if len(cache.Snapshot()) != 0 {
+		t.FailNow()
+	}
+}
+
+func TestCacheRefetch(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server which restarts, reassigning ids, after the
+	// first request
+	var calls, restarted int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		session := token
+		if atomic.LoadInt32(&restarted) == 1 {
+			session = "restarted"
+		}
+		w.Header().Add("X-Transmission-Session-Id", session)
+		if r.Header.Get("X-Transmission-Session-Id") != session {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+		if c.Arguments.Ids != nil {
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[],"removed":[]}}`))
+		} else if atomic.CompareAndSwapInt32(&restarted, 0, 1) {
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa"},{"id":2,"hashString":"bb"}]}}`))
+		} else {
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"bb"}]}}`))
+		}
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	tr := &Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 1}}
+	cache := Cache{Transmission: tr}
+	if err := cache.Sync(); err != nil || len(cache.Snapshot()) != 2 {
+		t.FailNow()
+	}
+
+	// verify a new session token replaces the cache, rather than keeping the
+	// torrent removed while the daemon was down
+	if err := cache.Sync(); err != nil || atomic.LoadInt32(&calls) != 3 {
+		t.Logf("error (%v) or unexpected calls: %d", err, atomic.LoadInt32(&calls))
+		t.FailNow()
+	}
+	if b, ok := cache.Torrent(ID(1)); !ok || b.HashString != "bb" || len(cache.Snapshot()) != 1 {
+		t.Logf("unexpected snapshot: %+v", cache.Snapshot())
+		t.FailNow()
+	}
+
+	// verify a sync older than the daemons window of activity fetches
+	// everything again
+	cache.synced = cache.synced.Add(-recentlyActiveWindow)
+	if err := cache.Sync(); err != nil || atomic.LoadInt32(&calls) != 4 || len(cache.Snapshot()) != 1 {
+		t.Logf("error (%v) or unexpected calls: %d", err, atomic.LoadInt32(&calls))
+		t.FailNow()
+	}
+}
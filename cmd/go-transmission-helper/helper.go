@@ -36,20 +36,27 @@ func (self *helper) load64(f string) (string, error) {
 	return base64.StdEncoding.EncodeToString(d), nil
 }
 
-func (self *helper) addFile(f string) error {
+// adds the file, returning the torrent added or nil when it was already known
+func (self *helper) addFile(f string) (transmission.TorrentID, error) {
 	self.Debug("adding torrent file %s", f)
 
 	meta, err := self.load64(f)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := self.Transmission.Add(meta); err != nil {
+	added, err := self.Transmission.AddTorrent(meta)
+	if errors.Is(err, transmission.ErrDuplicate) {
+		self.Info("transmission already has %s (%s)", f, added.Name)
+		return nil, nil
+	} else if err != nil {
 		self.Error("transmission failed to add metadata: %s", err)
-		return err
+		return nil, err
+	} else if added.Id == 0 && added.HashString == "" {
+		return nil, nil
 	}
 
-	return nil
+	return added.TorrentID(), nil
 }
 
 func (self *helper) add() error {
@@ -70,10 +77,14 @@ func (self *helper) add() error {
 		}
 	}
 
+	var added []transmission.TorrentID
 	if !d.IsDir() && d.Mode().IsRegular() {
-		if err = self.addFile(self.Add); err != nil {
+		id, err := self.addFile(self.Add)
+		if err != nil {
 			self.Error("failed to add %s (%s)", self.Add, err)
 			return err
+		} else if id != nil {
+			added = append(added, id)
 		}
 		remove(self.Add)
 	} else {
@@ -86,16 +97,25 @@ func (self *helper) add() error {
 		for _, f := range files {
 			if !f.IsDir() && strings.HasSuffix(f.Name(), ".torrent") {
 				a := path.Join(self.Add, f.Name())
-				if err = self.addFile(a); err != nil {
+				if id, err := self.addFile(a); err != nil {
 					self.Error("unable to load %s (%s)", a, err)
 				} else {
+					if id != nil {
+						added = append(added, id)
+					}
 					remove(a)
 				}
 			}
 		}
 	}
 
-	return self.Transmission.Resume()
+	// only start what was added, leaving deliberately paused torrents alone
+	self.Debug("starting added torrents: %v", added)
+	if err := self.Transmission.StartNow(added...); err != nil {
+		self.Error("failed to start added torrents: %s", err)
+		return err
+	}
+	return nil
 }
 
 func (self *helper) move() error {
@@ -117,7 +137,7 @@ func (self *helper) move() error {
 	self.Debug("list: %+v\n", list)
 
 	self.Debug("moving finished torrent downloads to %s", self.Move)
-	err = self.Transmission.Move(self.Move, list)
+	err = self.Transmission.Move(self.Move, transmission.Select(list...)...)
 	if err != nil {
 		self.Error("failed to move completed torrents: %s", err)
 		return err
@@ -126,7 +146,7 @@ func (self *helper) move() error {
 
 	if self.Remove {
 		self.Debug("removing complete torrents from transmission...")
-		err = self.Transmission.Remove(list)
+		err = self.Transmission.Remove(transmission.Select(list...)...)
 		if err != nil {
 			self.Error("failed to remove completed torrents: %s", err.Error())
 			return err
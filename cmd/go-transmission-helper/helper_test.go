@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/cdelorme/go-transmission-api"
 )
 
 type FakeFile struct {
@@ -34,12 +36,15 @@ var (
 	readdirError  error
 	removeError   error
 
-	fakeFile                = FakeFile{}
-	readdirFiles            = []os.FileInfo{&FakeFile{name: "test.torrent"}}
-	mockError               = errors.New("mock error...")
-	jsonTransmissionSuccess = []byte(`{"result":"success"}`)
-	jsonTransmissionList    = []byte(`{"result":"success","arguments": {"torrents": [{"id": 1,"isFinished": false},{"id": 2,"isFinished": false},{"id": 3,"isFinished": false},{"id": 4,"isFinished": false},{"id": 5,"isFinished": false},{"id": 6,"isFinished": true},{"id": 7,"isFinished": true},{"id": 8,"isFinished": true},{"id": 9,"isFinished": true},{"id": 10,"isFinished": true}]}}`)
-	token                   = `Some Long Crazy Hash`
+	fakeFile                  = FakeFile{}
+	readdirFiles              = []os.FileInfo{&FakeFile{name: "test.torrent"}}
+	mockError                 = errors.New("mock error...")
+	jsonTransmissionSuccess   = []byte(`{"result":"success"}`)
+	jsonTransmissionAdded     = []byte(`{"result":"success","arguments":{"torrent-added":{"id":11,"name":"test"}}}`)
+	jsonTransmissionDuplicate = []byte(`{"result":"success","arguments":{"torrent-duplicate":{"id":11,"name":"test"}}}`)
+	jsonTransmissionList      = []byte(`{"result":"success","arguments": {"torrents": [{"id": 1,"isFinished": false},{"id": 2,"isFinished": false},{"id": 3,"isFinished": false},{"id": 4,"isFinished": false},{"id": 5,"isFinished": false},{"id": 6,"isFinished": true},{"id": 7,"isFinished": true},{"id": 8,"isFinished": true},{"id": 9,"isFinished": true},{"id": 10,"isFinished": true}]}}`)
+	token                     = `Some Long Crazy Hash`
+	fastRetry                 = transmission.RetryPolicy{Delay: time.Millisecond}
 )
 
 func init() {
@@ -111,6 +116,7 @@ func TestHelperPrivateMove(t *testing.T) {
 	removeStatus := http.StatusOK
 	h := &helper{}
 	h.Remove = true
+	h.Transmission.Retry = fastRetry
 
 	// setup mock transmission endpoint
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -183,15 +189,34 @@ func TestHelperPrivateAdd(t *testing.T) {
 	readfileBytes = []byte("")
 	readfileError = nil
 	statError = nil
-	status := http.StatusOK
+	status, startStatus := http.StatusOK, http.StatusOK
 	h := &helper{}
+	h.Transmission.Retry = fastRetry
 
-	// setup mock transmission endpoint
+	// setup mock transmission endpoint, verifying only added torrents start
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
 		w.Header().Add("X-Transmission-Session-Id", token)
-		w.WriteHeader(status)
-		w.Write(jsonTransmissionSuccess)
+		var c struct {
+			Method    string
+			Arguments struct{ Ids []int }
+		}
+		json.NewDecoder(r.Body).Decode(&c)
+		switch c.Method {
+		case "torrent-add":
+			w.WriteHeader(status)
+			w.Write(jsonTransmissionAdded)
+		case "torrent-start-now":
+			if len(c.Arguments.Ids) != 1 || c.Arguments.Ids[0] != 11 {
+				t.Logf("unexpected start: %+v", c)
+				t.Fail()
+			}
+			w.WriteHeader(startStatus)
+			w.Write(jsonTransmissionSuccess)
+		default:
+			w.WriteHeader(status)
+			w.Write(jsonTransmissionSuccess)
+		}
 	}))
 	defer ts.Close()
 	h.Transmission.Port, _ = strconv.Atoi(strings.Split(ts.URL, ":")[2])
@@ -208,11 +233,18 @@ func TestHelperPrivateAdd(t *testing.T) {
 		t.FailNow()
 	}
 
-	// // test failure with dir at addfile
+	// // test failure with dir at addfile, which is only logged
 	status = http.StatusInternalServerError
+	if err := h.add(); err != nil {
+		t.FailNow()
+	}
+
+	// test failure with dir at start
+	status, startStatus = http.StatusOK, http.StatusInternalServerError
 	if err := h.add(); err == nil {
 		t.FailNow()
 	}
+	startStatus = http.StatusOK
 
 	// test failure with dir at readdir
 	readdirError = mockError
@@ -244,32 +276,41 @@ func TestHelperPrivateAddFile(t *testing.T) {
 	readfileBytes = []byte("")
 	readfileError = nil
 	status := http.StatusOK
+	body := jsonTransmissionAdded
 	h := &helper{}
+	h.Transmission.Retry = fastRetry
+	h.Transmission.Protocol = transmission.ProtocolLegacy
 
 	// setup mock transmission endpoint
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
 		w.Header().Add("X-Transmission-Session-Id", token)
 		w.WriteHeader(status)
-		w.Write(jsonTransmissionSuccess)
+		w.Write(body)
 	}))
 	defer ts.Close()
 	h.Transmission.Port, _ = strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// test running add file success
-	if err := h.addFile(""); err != nil {
+	if id, err := h.addFile(""); err != nil || id != transmission.ID(11) {
+		t.FailNow()
+	}
+
+	// test running add file with a duplicate
+	body = jsonTransmissionDuplicate
+	if id, err := h.addFile(""); err != nil || id != nil {
 		t.FailNow()
 	}
 
 	// test running add file failure at transmission
 	status = http.StatusInternalServerError
-	if err := h.addFile(""); err == nil {
+	if _, err := h.addFile(""); err == nil {
 		t.FailNow()
 	}
 
 	// test running add file failure at encoding
 	readfileError = mockError
-	if err := h.addFile(""); err == nil {
+	if _, err := h.addFile(""); err == nil {
 		t.FailNow()
 	}
 }
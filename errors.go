@@ -0,0 +1,82 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// classifications of a failed call, matched against an *Error via errors.Is
+var (
+	ErrUnauthorized = errors.New("transmission rejected the rpc credentials")
+	ErrUnreachable  = errors.New("transmission could not be reached")
+	ErrStatus       = errors.New("transmission replied with an unexpected http status")
+	ErrResult       = errors.New("transmission reported an unsuccessful result")
+	ErrMalformed    = errors.New("transmission replied with malformed json")
+)
+
+// returned without contacting the daemon when it is too old for a feature
+var ErrUnsupported = errors.New("transmission does not support this feature")
+
+// returned by AddTorrent along with the existing torrent when it was already added
+var ErrDuplicate = errors.New("transmission already has this torrent")
+
+// returned without contacting the daemon when a TorrentID cannot be sent
+var ErrInvalidID = errors.New("invalid torrent id")
+
+// returned when a request for one torrent matches none
+var ErrNotFound = errors.New("transmission has no such torrent")
+
+// describes a failed rpc call, from the last attempt made
+type Error struct {
+	Method   string
+	Status   int
+	Result   string
+	Attempts int
+	Err      error
+
+	// whether Err came from sending the request, rather than from preparing
+	// it locally, which is all ErrUnreachable matches
+	transport bool
+}
+
+func (self *Error) Error() string {
+	msg := "transmission " + self.Method + " failed"
+	if self.Attempts > 1 {
+		msg += " after " + strconv.Itoa(self.Attempts) + " attempts"
+	}
+	if self.Err != nil {
+		return msg + ": " + self.Err.Error()
+	} else if self.Result != "" {
+		return msg + ": " + self.Result
+	}
+	return msg + ": http status " + strconv.Itoa(self.Status)
+}
+
+func (self *Error) Unwrap() error {
+	return self.Err
+}
+
+func (self *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return self.Status == http.StatusUnauthorized
+	case ErrUnreachable:
+		return self.transport && self.Status == 0 && self.Err != nil && !errors.Is(self.Err, context.Canceled) && !errors.Is(self.Err, context.DeadlineExceeded)
+	case ErrStatus:
+		return self.Status != 0 && self.Status != http.StatusOK
+	case ErrResult:
+		return self.Status == http.StatusOK && self.Result != ""
+	}
+	return false
+}
+
+// whether a decode failed because of the body itself, rather than because
+// reading it was cut short (eg. by a timeout or a dropped connection)
+func isMalformed(err error) bool {
+	var syntax *json.SyntaxError
+	var kind *json.UnmarshalTypeError
+	return errors.As(err, &syntax) || errors.As(err, &kind) || errors.Is(err, ErrMalformed)
+}
@@ -0,0 +1,95 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorIs(t *testing.T) {
+	t.Parallel()
+
+	errs := []struct {
+		err     *Error
+		matches []error
+	}{
+		{&Error{Status: http.StatusUnauthorized}, []error{ErrUnauthorized, ErrStatus}},
+		{&Error{Status: http.StatusInternalServerError}, []error{ErrStatus}},
+		{&Error{Status: http.StatusOK, Result: "torrent not found"}, []error{ErrResult}},
+		{&Error{Err: fsError, transport: true}, []error{ErrUnreachable, fsError}},
+		{&Error{Err: fsError}, []error{fsError}},
+		{&Error{Err: context.Canceled}, []error{context.Canceled}},
+	}
+
+	for _, e := range errs {
+		for _, target := range []error{ErrUnauthorized, ErrUnreachable, ErrStatus, ErrResult, fsError, context.Canceled} {
+			var expect bool
+			for _, m := range e.matches {
+				expect = expect || m == target
+			}
+			if errors.Is(e.err, target) != expect {
+				t.Logf("expected errors.Is(%+v, %v) to be %v", e.err, target, expect)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	messages := map[string]*Error{
+		"transmission torrent-get failed: http status 500":                        {Method: "torrent-get", Status: 500, Attempts: 1},
+		"transmission torrent-remove failed after 3 attempts: torrent not found":  {Method: "torrent-remove", Status: 200, Result: "torrent not found", Attempts: 3},
+		"transmission torrent-add failed after 2 attempts: fake filesystem error": {Method: "torrent-add", Err: fsError, Attempts: 2},
+	}
+
+	for expect, e := range messages {
+		if e.Error() != expect {
+			t.Logf("expected %s, but got %s", expect, e.Error())
+			t.Fail()
+		}
+	}
+}
+
+func TestErrorFromSend(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server reporting a daemon failure
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"Couldn't move"}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the daemon result, method and attempts are kept
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Delay: time.Millisecond}}
+	err := tr.Move(movepath, ID(1))
+	var e *Error
+	if !errors.As(err, &e) || !errors.Is(err, ErrResult) || e.Method != "torrent-set-location" || e.Result != "Couldn't move" || e.Attempts != 3 {
+		t.Logf("unexpected error: %#v", err)
+		t.FailNow()
+	}
+
+	// verify an unreachable daemon keeps the transport error
+	ts.Close()
+	tr.Retry.Attempts = 1
+	err = tr.Remove(ID(1))
+	if !errors.As(err, &e) || !errors.Is(err, ErrUnreachable) || e.Err == nil || e.Status != 0 {
+		t.Logf("unexpected error: %#v", err)
+		t.FailNow()
+	}
+}
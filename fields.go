@@ -0,0 +1,151 @@
+package transmission
+
+import "context"
+
+// names a torrent-get field, which populates the Torrent member carrying
+// the same json tag (eg. FieldPercentDone populates PercentDone), except for
+// the per-file fields which are all merged into Files
+type Field string
+
+const (
+	FieldId                  Field = "id"
+	FieldHashString          Field = "hashString"
+	FieldName                Field = "name"
+	FieldComment             Field = "comment"
+	FieldCreator             Field = "creator"
+	FieldMagnetLink          Field = "magnetLink"
+	FieldGroup               Field = "group"
+	FieldLabels              Field = "labels"
+	FieldMimeType            Field = "primary-mime-type"
+	FieldStatus              Field = "status"
+	FieldFinished            Field = "isFinished"
+	FieldPrivate             Field = "isPrivate"
+	FieldStalled             Field = "isStalled"
+	FieldError               Field = "error"
+	FieldErrorString         Field = "errorString"
+	FieldDownloadDir         Field = "downloadDir"
+	FieldTorrentFile         Field = "torrentFile"
+	FieldFileCount           Field = "file-count"
+	FieldPieceCount          Field = "pieceCount"
+	FieldPieceSize           Field = "pieceSize"
+	FieldPieces              Field = "pieces"
+	FieldTotalSize           Field = "totalSize"
+	FieldSizeWhenDone        Field = "sizeWhenDone"
+	FieldLeftUntilDone       Field = "leftUntilDone"
+	FieldDesiredAvailable    Field = "desiredAvailable"
+	FieldHaveUnchecked       Field = "haveUnchecked"
+	FieldHaveValid           Field = "haveValid"
+	FieldCorruptEver         Field = "corruptEver"
+	FieldDownloadedEver      Field = "downloadedEver"
+	FieldUploadedEver        Field = "uploadedEver"
+	FieldPercentComplete     Field = "percentComplete"
+	FieldPercentDone         Field = "percentDone"
+	FieldMetadataPercent     Field = "metadataPercentComplete"
+	FieldRecheckProgress     Field = "recheckProgress"
+	FieldUploadRatio         Field = "uploadRatio"
+	FieldRateDownload        Field = "rateDownload"
+	FieldRateUpload          Field = "rateUpload"
+	FieldDownloadLimit       Field = "downloadLimit"
+	FieldDownloadLimited     Field = "downloadLimited"
+	FieldUploadLimit         Field = "uploadLimit"
+	FieldUploadLimited       Field = "uploadLimited"
+	FieldHonorsSessionLimits Field = "honorsSessionLimits"
+	FieldBandwidthPriority   Field = "bandwidthPriority"
+	FieldQueuePosition       Field = "queuePosition"
+	FieldPeerLimit           Field = "peer-limit"
+	FieldMaxConnectedPeers   Field = "maxConnectedPeers"
+	FieldWebseedsSendingToUs Field = "webseedsSendingToUs"
+	FieldSeedRatioLimit      Field = "seedRatioLimit"
+	FieldSeedRatioMode       Field = "seedRatioMode"
+	FieldSeedIdleLimit       Field = "seedIdleLimit"
+	FieldSeedIdleMode        Field = "seedIdleMode"
+	FieldTrackerList         Field = "trackerList"
+	FieldSequentialDownload  Field = "sequentialDownload"
+	FieldActivityDate        Field = "activityDate"
+	FieldAddedDate           Field = "addedDate"
+	FieldDateCreated         Field = "dateCreated"
+	FieldDoneDate            Field = "doneDate"
+	FieldEditDate            Field = "editDate"
+	FieldStartDate           Field = "startDate"
+	FieldManualAnnounceTime  Field = "manualAnnounceTime"
+	FieldETA                 Field = "eta"
+	FieldETAIdle             Field = "etaIdle"
+	FieldSecondsDownloading  Field = "secondsDownloading"
+	FieldSecondsSeeding      Field = "secondsSeeding"
+	FieldFiles               Field = "files"
+	FieldFileStats           Field = "fileStats"
+	FieldPriorities          Field = "priorities"
+	FieldWanted              Field = "wanted"
+	FieldPeers               Field = "peers"
+	FieldPeersFrom           Field = "peersFrom"
+	FieldPeersConnected      Field = "peersConnected"
+	FieldPeersGettingFromUs  Field = "peersGettingFromUs"
+	FieldPeersSendingToUs    Field = "peersSendingToUs"
+	FieldTrackers            Field = "trackers"
+	FieldTrackerStats        Field = "trackerStats"
+)
+
+// minimum rpc-version for fields newer than the original spec
+var fieldVersions = map[Field]int{
+	FieldLabels:             16,
+	FieldEditDate:           16,
+	FieldFileCount:          17,
+	FieldGroup:              17,
+	FieldMimeType:           17,
+	FieldPercentComplete:    17,
+	FieldTrackerList:        17,
+	FieldSequentialDownload: 18,
+}
+
+// requests only the named fields, plus the id which is always included so
+// that results remain addressable; members for other fields are left zero
+// @link: https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+func (self *Transmission) GetFields(fields ...Field) ([]Torrent, error) {
+	return self.GetFieldsContext(context.Background(), fields...)
+}
+
+func (self *Transmission) GetFieldsContext(ctx context.Context, fields ...Field) ([]Torrent, error) {
+	return self.torrents(ctx, nil, fields...)
+}
+
+// requests the fields for the supplied ids, or every torrent when empty
+func (self *Transmission) torrents(ctx context.Context, ids []TorrentID, fields ...Field) ([]Torrent, error) {
+	args, err := self.torrentGet(ctx, ids, fields...)
+	return args.Torrents, err
+}
+
+// requests the fields, returning the arguments so the removed ids which
+// accompany recently-active requests are available
+func (self *Transmission) torrentGet(ctx context.Context, ids []TorrentID, fields ...Field) (arguments, error) {
+	cmd, err := self.torrentCommand(ctx, ids, fields...)
+	if err != nil {
+		return arguments{}, err
+	}
+	return self.send(ctx, cmd)
+}
+
+// builds the torrent-get for the fields, checking the daemon supports them
+func (self *Transmission) torrentCommand(ctx context.Context, ids []TorrentID, fields ...Field) (*command, error) {
+	names := []string{string(FieldId)}
+	for _, f := range fields {
+		if need, ok := fieldVersions[f]; ok {
+			if err := self.require(ctx, string(f), need); err != nil {
+				return nil, err
+			}
+		}
+		if f != FieldId && !contains(names, string(f)) {
+			names = append(names, string(f))
+		}
+	}
+	wire, err := wireIds(ids)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &command{Method: "torrent-get", Arguments: arguments{Ids: wire, Fields: names}}
+	if table, err := self.tables(ctx); err != nil {
+		return nil, err
+	} else if table {
+		cmd.Arguments.Format = "table"
+	}
+	return cmd, nil
+}
@@ -0,0 +1,82 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// prepares a false test server advertising the supplied rpc-version, which
+// records the fields requested by torrent-get
+func fieldsServer(t *testing.T, rpc int, requested *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		w.WriteHeader(http.StatusOK)
+		if c.Method == "session-get" {
+			w.Write([]byte(`{"result":"success","arguments":{"rpc-version":` + strconv.Itoa(rpc) + `}}`))
+			return
+		}
+		*requested = c.Arguments.Fields
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"name":"one","percentDone":0.5,"labels":["a"]},{"id":2,"name":"two","percentDone":1}]}}`))
+	}))
+}
+
+func TestGetFields(t *testing.T) {
+	t.Parallel()
+
+	var requested []string
+	ts := fieldsServer(t, 17, &requested)
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify only the named fields, plus the id, are requested
+	tr := Transmission{Port: port}
+	l, err := tr.GetFields(FieldName, FieldPercentDone, FieldLabels, FieldName)
+	if err != nil || len(l) != 2 || l[0].Name != "one" || l[0].PercentDone != 0.5 || len(l[0].Labels) != 1 || l[1].PercentDone != 1 {
+		t.Logf("error (%v) or unexpected list: %+v", err, l)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(requested, []string{"id", "name", "percentDone", "labels"}) {
+		t.Logf("unexpected fields requested: %v", requested)
+		t.FailNow()
+	}
+
+	// verify Get asks only for the finished state
+	if _, err := tr.Get(); err != nil || !reflect.DeepEqual(requested, []string{"id", "isFinished"}) {
+		t.Logf("error (%v) or unexpected fields requested: %v", err, requested)
+		t.FailNow()
+	}
+}
+
+func TestGetFieldsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var requested []string
+	ts := fieldsServer(t, 15, &requested)
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify newer fields are refused before sending
+	tr := Transmission{Port: port}
+	if _, err := tr.GetFields(FieldName, FieldTrackerList); !errors.Is(err, ErrUnsupported) || requested != nil {
+		t.Logf("expected unsupported error, but got: %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,217 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// download priority of a file, or the bandwidth priority of a torrent
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+func (self Priority) String() string {
+	switch self {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+	return "priority(" + strconv.Itoa(int(self)) + ")"
+}
+
+// a file within a torrent, where the name is the path relative to the
+// download directory
+type File struct {
+	Name           string   `json:"name"`
+	Length         int64    `json:"length"`
+	BytesCompleted int64    `json:"bytesCompleted"`
+	Priority       Priority `json:"priority"`
+	Wanted         bool     `json:"wanted"`
+}
+
+// fraction of the file downloaded, between 0 and 1
+func (self File) Progress() float64 {
+	if self.Length <= 0 {
+		return 1
+	}
+	return float64(self.BytesCompleted) / float64(self.Length)
+}
+
+func (self File) Done() bool {
+	return self.BytesCompleted >= self.Length
+}
+
+type fileWire struct {
+	Name           string `json:"name"`
+	Length         int64  `json:"length"`
+	BytesCompleted int64  `json:"bytesCompleted"`
+}
+
+type fileStatWire struct {
+	BytesCompleted int64    `json:"bytesCompleted"`
+	Priority       Priority `json:"priority"`
+	Wanted         flag     `json:"wanted"`
+}
+
+// a boolean the daemon may send as a number
+type flag bool
+
+func (self *flag) UnmarshalJSON(d []byte) error {
+	var b bool
+	if err := json.Unmarshal(d, &b); err == nil {
+		*self = flag(b)
+		return nil
+	}
+	var n int
+	if err := json.Unmarshal(d, &n); err != nil {
+		return err
+	}
+	*self = n != 0
+	return nil
+}
+
+// merges whichever per-file arrays were received into Files, growing it to
+// fit the longest, so each field may be requested on its own
+func (self *Torrent) mergeFiles(w *torrentWire) {
+	size := len(self.Files)
+	for _, n := range []int{len(w.Files), len(w.FileStats), len(w.Priorities), len(w.Wanted)} {
+		if n > size {
+			size = n
+		}
+	}
+	if size > len(self.Files) {
+		self.Files = append(self.Files, make([]File, size-len(self.Files))...)
+	}
+
+	for i, f := range w.Files {
+		self.Files[i].Name, self.Files[i].Length, self.Files[i].BytesCompleted = f.Name, f.Length, f.BytesCompleted
+	}
+	for i, f := range w.FileStats {
+		self.Files[i].BytesCompleted, self.Files[i].Priority, self.Files[i].Wanted = f.BytesCompleted, f.Priority, bool(f.Wanted)
+	}
+	for i, p := range w.Priorities {
+		self.Files[i].Priority = p
+	}
+	for i, wanted := range w.Wanted {
+		self.Files[i].Wanted = bool(wanted)
+	}
+}
+
+// lists the files of the supplied torrents, or of every torrent when none
+// are supplied, keyed by the hash of each torrent
+func (self *Transmission) Files(ids ...TorrentID) (map[TorrentID][]File, error) {
+	return self.FilesContext(context.Background(), ids...)
+}
+
+func (self *Transmission) FilesContext(ctx context.Context, ids ...TorrentID) (map[TorrentID][]File, error) {
+	torrents, err := self.torrents(ctx, ids, FieldHashString, FieldFiles, FieldFileStats)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[TorrentID][]File, len(torrents))
+	for _, t := range torrents {
+		files[t.TorrentID()] = t.Files
+	}
+	return files, nil
+}
+
+// marks the files of one torrent, by their index in Files, as wanted or not
+func (self *Transmission) SetWanted(id TorrentID, wanted bool, files ...int) error {
+	return self.SetWantedContext(context.Background(), id, wanted, files...)
+}
+
+func (self *Transmission) SetWantedContext(ctx context.Context, id TorrentID, wanted bool, files ...int) error {
+	if err := single(id, "file selection"); err != nil {
+		return err
+	}
+	settings := TorrentSettings{FilesUnwanted: files}
+	if wanted {
+		settings = TorrentSettings{FilesWanted: files}
+	}
+	return self.SetContext(ctx, settings, id)
+}
+
+// sets the download priority of the files of one torrent, by their index
+func (self *Transmission) SetPriority(id TorrentID, priority Priority, files ...int) error {
+	return self.SetPriorityContext(context.Background(), id, priority, files...)
+}
+
+func (self *Transmission) SetPriorityContext(ctx context.Context, id TorrentID, priority Priority, files ...int) error {
+	if err := single(id, "file priority"); err != nil {
+		return err
+	}
+	var settings TorrentSettings
+	switch priority {
+	case PriorityHigh:
+		settings.FilesPriorityHigh = files
+	case PriorityNormal:
+		settings.FilesPriorityNormal = files
+	case PriorityLow:
+		settings.FilesPriorityLow = files
+	default:
+		return fmt.Errorf("unknown file priority %d", priority)
+	}
+	return self.SetContext(ctx, settings, id)
+}
+
+// lists the indices of the files of one torrent for which match is true,
+// for passing to SetWanted or SetPriority
+func (self *Transmission) MatchFiles(id TorrentID, match func(File) bool) ([]int, error) {
+	return self.MatchFilesContext(context.Background(), id, match)
+}
+
+func (self *Transmission) MatchFilesContext(ctx context.Context, id TorrentID, match func(File) bool) ([]int, error) {
+	if err := single(id, "file matching"); err != nil {
+		return nil, err
+	}
+	torrents, err := self.torrents(ctx, []TorrentID{id}, FieldFiles)
+	if err != nil {
+		return nil, err
+	} else if len(torrents) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	var indices []int
+	for i, f := range torrents[0].Files {
+		if match(f) {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
+
+// matches files whose path, or any trailing part of it, matches the glob, so
+// patterns need not name the top level folder of multi-file torrents (eg.
+// "*sample*" or "extras/*" both match "Movie/extras/sample.mkv")
+func Glob(pattern string) (func(File) bool, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return func(f File) bool {
+		segments := strings.Split(f.Name, "/")
+		for i := range segments {
+			if ok, _ := path.Match(pattern, strings.Join(segments[i:], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// matches files whose path matches the expression
+func Regexp(re *regexp.Regexp) func(File) bool {
+	return func(f File) bool {
+		return re.MatchString(f.Name)
+	}
+}
@@ -0,0 +1,248 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPriorityString(t *testing.T) {
+	t.Parallel()
+
+	if PriorityLow.String() != "low" || PriorityNormal.String() != "normal" || PriorityHigh.String() != "high" || Priority(5).String() != "priority(5)" {
+		t.FailNow()
+	}
+}
+
+func TestFileProgress(t *testing.T) {
+	t.Parallel()
+
+	if f := (File{Length: 200, BytesCompleted: 50}); f.Progress() != 0.25 || f.Done() {
+		t.FailNow()
+	}
+	if f := (File{}); f.Progress() != 1 || !f.Done() {
+		t.FailNow()
+	}
+}
+
+func TestTorrentMergeFiles(t *testing.T) {
+	t.Parallel()
+
+	// verify files and fileStats merge, with numeric wanted flags
+	var tr Torrent
+	d := []byte(`{"id":1,"files":[{"name":"a/movie.mkv","length":1000,"bytesCompleted":10},{"name":"a/sample.mkv","length":100,"bytesCompleted":0}],
+		"fileStats":[{"bytesCompleted":500,"wanted":1,"priority":1},{"bytesCompleted":0,"wanted":0,"priority":-1}]}`)
+	if err := json.Unmarshal(d, &tr); err != nil {
+		t.Fatal(err)
+	}
+	expect := []File{
+		{Name: "a/movie.mkv", Length: 1000, BytesCompleted: 500, Priority: PriorityHigh, Wanted: true},
+		{Name: "a/sample.mkv", Length: 100, Priority: PriorityLow},
+	}
+	if !reflect.DeepEqual(tr.Files, expect) {
+		t.Logf("unexpected files: %+v", tr.Files)
+		t.FailNow()
+	}
+
+	// verify the flat priorities and wanted arrays apply on their own
+	tr = Torrent{}
+	if err := json.Unmarshal([]byte(`{"priorities":[0,1],"wanted":[true,false]}`), &tr); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Files) != 2 || tr.Files[0].Priority != PriorityNormal || !tr.Files[0].Wanted || tr.Files[1].Priority != PriorityHigh || tr.Files[1].Wanted {
+		t.Logf("unexpected files: %+v", tr.Files)
+		t.FailNow()
+	}
+
+	// verify malformed flags are reported
+	if err := json.Unmarshal([]byte(`{"wanted":["yes"]}`), &tr); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		// verify request
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		if c.Method != "torrent-get" || !reflect.DeepEqual(c.Arguments.Ids, []interface{}{float64(3)}) || !reflect.DeepEqual(c.Arguments.Fields, []string{"id", "hashString", "files", "fileStats"}) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":3,"hashString":"3333333333333333333333333333333333333333","files":[{"name":"a.txt","length":10,"bytesCompleted":5}],"fileStats":[{"bytesCompleted":5,"wanted":true,"priority":0}]}]}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// run Files
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	files, err := tr.Files(ID(3))
+	hash := Hash(strings.Repeat("3", 40))
+	if err != nil || len(files[hash]) != 1 || files[hash][0] != (File{Name: "a.txt", Length: 10, BytesCompleted: 5, Wanted: true}) {
+		t.Logf("error (%v) or unexpected files: %+v", err, files)
+		t.FailNow()
+	}
+
+	// verify failures are returned
+	ts.Close()
+	tr.Retry.Attempts = 1
+	if _, err := tr.Files(ID(3)); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestFilesJSONRPC(t *testing.T) {
+	t.Parallel()
+
+	// verify the snake_case per-file fields reach the model
+	rc := &command{}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","result":{"torrents":[{"id":1,"files":[{"name":"a","length":2,"bytes_completed":1}],"file_stats":[{"bytes_completed":1,"wanted":true,"priority":1}]}]},"id":1}`), rc)
+	if err != nil || len(rc.Arguments.Torrents) != 1 || rc.Arguments.Torrents[0].Files[0] != (File{Name: "a", Length: 2, BytesCompleted: 1, Priority: PriorityHigh, Wanted: true}) {
+		t.Logf("error (%v) or unexpected arguments: %+v", err, rc.Arguments)
+		t.FailNow()
+	}
+}
+
+func TestSelectFiles(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server recording the file lists of each torrent-set
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		var c struct {
+			Method    string
+			Arguments map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&c)
+		delete(c.Arguments, "ids")
+		received = append(received, fmt.Sprint(c.Method, c.Arguments))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify each selection is sent under its own key
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if tr.SetWanted(ID(1), false, 0, 2) != nil || tr.SetWanted(ID(1), true, 1) != nil || tr.SetPriority(ID(1), PriorityHigh, 3) != nil || tr.SetPriority(ID(1), PriorityLow, 4) != nil || tr.SetPriority(ID(1), PriorityNormal, 5) != nil {
+		t.FailNow()
+	}
+
+	// verify empty selections, which the daemon applies to every file, are never sent
+	if tr.SetWanted(ID(1), false) != nil || tr.SetPriority(ID(1), PriorityHigh) != nil {
+		t.FailNow()
+	}
+	expected := []string{
+		"torrent-setmap[files-unwanted:[0 2]]",
+		"torrent-setmap[files-wanted:[1]]",
+		"torrent-setmap[priority-high:[3]]",
+		"torrent-setmap[priority-low:[4]]",
+		"torrent-setmap[priority-normal:[5]]",
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Logf("unexpected requests: %q", received)
+		t.FailNow()
+	}
+
+	// verify unknown priorities and selectors are rejected
+	if tr.SetPriority(ID(1), Priority(7), 1) == nil || !errors.Is(tr.SetWanted(RecentlyActive, true, 1), ErrInvalidID) {
+		t.FailNow()
+	}
+}
+
+func TestMatchFiles(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		w.WriteHeader(http.StatusOK)
+		if reflect.DeepEqual(c.Arguments.Ids, []interface{}{float64(99)}) {
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[]}}`))
+			return
+		}
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"files":[
+			{"name":"Movie/movie.mkv","length":10},
+			{"name":"Movie/Sample/sample.mkv","length":1},
+			{"name":"Movie/Extras/interview.mkv","length":2},
+			{"name":"Movie/movie.nfo","length":1}]}]}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+
+	// verify globs match the full path or the final component
+	glob, err := Glob("*sample*")
+	if err != nil {
+		t.FailNow()
+	}
+	if indices, err := tr.MatchFiles(Hash(hash), glob); err != nil || !reflect.DeepEqual(indices, []int{1}) {
+		t.Logf("error (%v) or unexpected indices: %v", err, indices)
+		t.FailNow()
+	}
+	for _, pattern := range []string{"Movie/Extras/*", "Extras/*", "*/interview.mkv"} {
+		glob, _ = Glob(pattern)
+		if indices, err := tr.MatchFiles(ID(1), glob); err != nil || !reflect.DeepEqual(indices, []int{2}) {
+			t.Logf("error (%v) or unexpected indices for %s: %v", err, pattern, indices)
+			t.FailNow()
+		}
+	}
+
+	// verify expressions match the full path
+	if indices, err := tr.MatchFiles(ID(1), Regexp(regexp.MustCompile(`(?i)/(sample|extras)/`))); err != nil || !reflect.DeepEqual(indices, []int{1, 2}) {
+		t.Logf("error (%v) or unexpected indices: %v", err, indices)
+		t.FailNow()
+	}
+
+	// verify bad patterns and selectors are rejected
+	if _, err := Glob("[a"); err == nil {
+		t.FailNow()
+	}
+	if _, err := tr.MatchFiles(RecentlyActive, Regexp(regexp.MustCompile("."))); !errors.Is(err, ErrInvalidID) {
+		t.FailNow()
+	}
+
+	// verify a torrent the daemon does not have is reported
+	if _, err := tr.MatchFiles(ID(99), glob); !errors.Is(err, ErrNotFound) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
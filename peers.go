@@ -0,0 +1,62 @@
+package transmission
+
+import "context"
+
+// a connected peer, where rates are in bytes per second and progress is the
+// fraction of the torrent the peer has, between 0 and 1
+type Peer struct {
+	Address            string  `json:"address"`
+	Port               int     `json:"port"`
+	ClientName         string  `json:"clientName"`
+	FlagStr            string  `json:"flagStr"`
+	Progress           float64 `json:"progress"`
+	RateToClient       int     `json:"rateToClient"`
+	RateToPeer         int     `json:"rateToPeer"`
+	ClientIsChoked     bool    `json:"clientIsChoked"`
+	ClientIsInterested bool    `json:"clientIsInterested"`
+	PeerIsChoked       bool    `json:"peerIsChoked"`
+	PeerIsInterested   bool    `json:"peerIsInterested"`
+	IsDownloadingFrom  bool    `json:"isDownloadingFrom"`
+	IsUploadingTo      bool    `json:"isUploadingTo"`
+	IsEncrypted        bool    `json:"isEncrypted"`
+	IsIncoming         bool    `json:"isIncoming"`
+	IsUTP              bool    `json:"isUTP"`
+}
+
+// counts of connected peers by how they were discovered
+type PeerSources struct {
+	Cache    int `json:"fromCache"`
+	Dht      int `json:"fromDht"`
+	Incoming int `json:"fromIncoming"`
+	Lpd      int `json:"fromLpd"`
+	Ltep     int `json:"fromLtep"`
+	Pex      int `json:"fromPex"`
+	Tracker  int `json:"fromTracker"`
+}
+
+// the peers of a torrent, with the counts the daemon keeps about them
+type Swarm struct {
+	Peers         []Peer
+	From          PeerSources
+	Connected     int
+	GettingFromUs int
+	SendingToUs   int
+}
+
+// lists the peers of the supplied torrents, or of every torrent when none
+// are supplied, keyed by the hash of each torrent
+func (self *Transmission) Peers(ids ...TorrentID) (map[TorrentID]Swarm, error) {
+	return self.PeersContext(context.Background(), ids...)
+}
+
+func (self *Transmission) PeersContext(ctx context.Context, ids ...TorrentID) (map[TorrentID]Swarm, error) {
+	torrents, err := self.torrents(ctx, ids, FieldHashString, FieldPeers, FieldPeersFrom, FieldPeersConnected, FieldPeersGettingFromUs, FieldPeersSendingToUs)
+	if err != nil {
+		return nil, err
+	}
+	swarms := make(map[TorrentID]Swarm, len(torrents))
+	for _, t := range torrents {
+		swarms[t.TorrentID()] = Swarm{Peers: t.Peers, From: t.PeersFrom, Connected: t.PeersConnected, GettingFromUs: t.PeersGettingFromUs, SendingToUs: t.PeersSendingToUs}
+	}
+	return swarms, nil
+}
@@ -0,0 +1,80 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPeers(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		// verify request
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		if c.Method != "torrent-get" || !reflect.DeepEqual(c.Arguments.Fields, []string{"id", "hashString", "peers", "peersFrom", "peersConnected", "peersGettingFromUs", "peersSendingToUs"}) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":4,"hashString":"4444444444444444444444444444444444444444",
+			"peers":[{"address":"10.1.1.1","port":51413,"clientName":"Transmission 4.0.5","flagStr":"TDEI","progress":0.75,"rateToClient":1024,"rateToPeer":0,"isEncrypted":true,"isUTP":true,"isDownloadingFrom":true}],
+			"peersFrom":{"fromCache":0,"fromDht":3,"fromIncoming":1,"fromLpd":0,"fromLtep":0,"fromPex":2,"fromTracker":5},
+			"peersConnected":11,"peersGettingFromUs":2,"peersSendingToUs":1}]}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// run Peers
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	swarms, err := tr.Peers(ID(4))
+	if err != nil || len(swarms) != 1 {
+		t.Logf("error (%v) or unexpected swarms: %+v", err, swarms)
+		t.FailNow()
+	}
+	s := swarms[Hash(strings.Repeat("4", 40))]
+	peer := Peer{Address: "10.1.1.1", Port: 51413, ClientName: "Transmission 4.0.5", FlagStr: "TDEI", Progress: 0.75, RateToClient: 1024, IsEncrypted: true, IsUTP: true, IsDownloadingFrom: true}
+	if len(s.Peers) != 1 || s.Peers[0] != peer || s.From != (PeerSources{Dht: 3, Incoming: 1, Pex: 2, Tracker: 5}) || s.Connected != 11 || s.GettingFromUs != 2 || s.SendingToUs != 1 {
+		t.Logf("unexpected swarm: %+v", s)
+		t.FailNow()
+	}
+
+	// verify failures are returned
+	ts.Close()
+	tr.Retry.Attempts = 1
+	if _, err := tr.Peers(); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestPeersJSONRPC(t *testing.T) {
+	t.Parallel()
+
+	// verify acronyms and nested snake_case names reach the model
+	rc := &command{}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","result":{"torrents":[{"id":1,"peers":[{"client_name":"x","is_utp":true}],"peers_from":{"from_dht":2}}]},"id":1}`), rc)
+	if err != nil || len(rc.Arguments.Torrents) != 1 {
+		t.FailNow()
+	}
+	tr := rc.Arguments.Torrents[0]
+	if len(tr.Peers) != 1 || tr.Peers[0].ClientName != "x" || !tr.Peers[0].IsUTP || tr.PeersFrom.Dht != 2 {
+		t.Logf("unexpected torrent: %+v", tr)
+		t.FailNow()
+	}
+}
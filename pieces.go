@@ -0,0 +1,131 @@
+package transmission
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// the pieces a torrent has, decoded from the base64 bitfield where the most
+// significant bit of the first byte is piece zero
+type PieceMap struct {
+	bits  []byte
+	count int
+	size  int64
+}
+
+// a run of consecutive pieces from Start up to but excluding End
+type PieceRange struct {
+	Start int
+	End   int
+	Have  bool
+}
+
+func (self PieceRange) Len() int {
+	return self.End - self.Start
+}
+
+// builds a piece map from a raw bitfield, which is how the daemon sends it
+// before base64 encoding
+func NewPieceMap(bits []byte, count int, size int64) PieceMap {
+	return PieceMap{bits: bits, count: count, size: size}
+}
+
+func (self *PieceMap) UnmarshalJSON(d []byte) error {
+	var s string
+	if err := json.Unmarshal(d, &s); err != nil {
+		return err
+	}
+	bits, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	} else if len(bits) == 0 {
+		bits = nil
+	}
+	self.bits = bits
+	return nil
+}
+
+func (self PieceMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.StdEncoding.EncodeToString(self.bits))
+}
+
+// the number of pieces, which counts the padding bits of the final byte when
+// pieceCount was not requested alongside pieces
+func (self PieceMap) Len() int {
+	if self.count > 0 {
+		return self.count
+	}
+	return len(self.bits) * 8
+}
+
+// the size of each piece in bytes, or zero when pieceSize was not requested
+func (self PieceMap) Size() int64 {
+	return self.size
+}
+
+func (self PieceMap) Has(i int) bool {
+	if i < 0 || i >= self.Len() || i/8 >= len(self.bits) {
+		return false
+	}
+	return self.bits[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+func (self PieceMap) Have() int {
+	var n int
+	for i := 0; i < self.Len(); i++ {
+		if self.Has(i) {
+			n++
+		}
+	}
+	return n
+}
+
+func (self PieceMap) Missing() int {
+	return self.Len() - self.Have()
+}
+
+// calls fn with each run of pieces that are all had or all missing, in
+// order, stopping early when fn returns false
+func (self PieceMap) Ranges(fn func(PieceRange) bool) {
+	for start := 0; start < self.Len(); {
+		have := self.Has(start)
+		end := start + 1
+		for end < self.Len() && self.Has(end) == have {
+			end++
+		}
+		if !fn(PieceRange{Start: start, End: end, Have: have}) {
+			return
+		}
+		start = end
+	}
+}
+
+// draws the map in at most width characters, where each character covers an
+// equal share of the pieces and is '#' when all are had, '.' when none are,
+// and '+' when some are; a width of zero or less draws one per piece
+func (self PieceMap) Render(width int) string {
+	n := self.Len()
+	if width <= 0 || width > n {
+		width = n
+	}
+	var b strings.Builder
+	for c := 0; c < width; c++ {
+		start, end := c*n/width, (c+1)*n/width
+		var have int
+		for i := start; i < end; i++ {
+			if self.Has(i) {
+				have++
+			}
+		}
+		switch {
+		case have == end-start:
+			b.WriteByte('#')
+		case have == 0:
+			b.WriteByte('.')
+		default:
+			b.WriteByte('+')
+		}
+	}
+	return b.String()
+}
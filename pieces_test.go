@@ -0,0 +1,91 @@
+package transmission
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPieceMap(t *testing.T) {
+	t.Parallel()
+
+	// pieces 0-3 and 9 are had out of ten, the rest of the final byte is padding
+	p := NewPieceMap([]byte{0xF0, 0x40}, 10, 16384)
+	if p.Len() != 10 || p.Size() != 16384 || p.Have() != 5 || p.Missing() != 5 {
+		t.Logf("unexpected counts: %d %d %d", p.Len(), p.Have(), p.Missing())
+		t.FailNow()
+	}
+	if !p.Has(0) || p.Has(4) || !p.Has(9) || p.Has(10) || p.Has(-1) {
+		t.FailNow()
+	}
+
+	// verify ranges cover every piece in order
+	var ranges []PieceRange
+	p.Ranges(func(r PieceRange) bool {
+		ranges = append(ranges, r)
+		return true
+	})
+	expected := []PieceRange{{0, 4, true}, {4, 9, false}, {9, 10, true}}
+	if !reflect.DeepEqual(ranges, expected) || ranges[1].Len() != 5 {
+		t.Logf("unexpected ranges: %+v", ranges)
+		t.FailNow()
+	}
+
+	// verify ranges stop early
+	var calls int
+	p.Ranges(func(PieceRange) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.FailNow()
+	}
+
+	// verify rendering at full and reduced width
+	if s := p.Render(0); s != "####.....#" {
+		t.Logf("unexpected render: %s", s)
+		t.FailNow()
+	}
+	if s := p.Render(5); s != "##..+" {
+		t.Logf("unexpected render: %s", s)
+		t.FailNow()
+	}
+
+	// without a count every bit is a piece
+	if NewPieceMap([]byte{0xFF}, 0, 0).Len() != 8 {
+		t.FailNow()
+	}
+}
+
+func TestPieceMapJSON(t *testing.T) {
+	t.Parallel()
+
+	// verify the torrent sizes the decoded bitfield
+	var tr Torrent
+	if err := json.Unmarshal([]byte(`{"pieces":"8EA=","pieceCount":10,"pieceSize":16384}`), &tr); err != nil {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+	if tr.Pieces.Len() != 10 || tr.Pieces.Size() != 16384 || tr.Pieces.Have() != 5 {
+		t.Logf("unexpected pieces: %+v", tr.Pieces)
+		t.FailNow()
+	}
+
+	// verify the bitfield round trips
+	d, err := json.Marshal(tr.Pieces)
+	if err != nil || string(d) != `"8EA="` {
+		t.Logf("error (%v) or unexpected encoding: %s", err, d)
+		t.FailNow()
+	}
+
+	// verify an empty bitfield decodes to the zero piece map
+	var empty PieceMap
+	if err := json.Unmarshal([]byte(`""`), &empty); err != nil || !reflect.DeepEqual(empty, PieceMap{}) {
+		t.FailNow()
+	}
+
+	// verify malformed bitfields are rejected
+	if err := json.Unmarshal([]byte(`{"pieces":"!"}`), &tr); err == nil {
+		t.FailNow()
+	}
+}
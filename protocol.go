@@ -0,0 +1,299 @@
+package transmission
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+)
+
+// the first rpc-version to speak json-rpc 2.0 with snake_case names
+const jsonrpcVersion = 18
+
+// selects the wire format used to talk to the daemon
+type Protocol int
+
+const (
+	// negotiates the protocol from the daemons advertised rpc-version
+	ProtocolAuto Protocol = iota
+
+	// the method, arguments and result envelope with kebab and camel names
+	ProtocolLegacy
+
+	// json-rpc 2.0 with snake_case names, from transmission 4.1
+	ProtocolJSONRPC
+)
+
+// translates commands to and from a wire format
+type protocol interface {
+	encode(*command) ([]byte, error)
+	decode(io.Reader, *command) error
+}
+
+type legacy struct{}
+
+func (legacy) encode(cmd *command) ([]byte, error) {
+	return json.Marshal(cmd)
+}
+
+func (legacy) decode(r io.Reader, rc *command) error {
+	dec := json.NewDecoder(r)
+	if rc.each == nil {
+		return dec.Decode(rc)
+	}
+	return members(dec, func(key string) error {
+		switch key {
+		case "result":
+			return dec.Decode(&rc.Result)
+		case "arguments":
+			return streamArguments(dec, rc, nil)
+		}
+		return dec.Decode(new(json.RawMessage))
+	})
+}
+
+var requestId int64
+
+type jsonrpc struct{}
+
+type jsonrpcRequest struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	Id      int64       `json:"id"`
+}
+
+type jsonrpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpcError   `json:"error"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		ErrorString string `json:"error_string"`
+	} `json:"data"`
+}
+
+// the text reported as the result of a failed request
+func (self *jsonrpcError) result() string {
+	if self.Data.ErrorString != "" {
+		return self.Data.ErrorString
+	}
+	return self.Message
+}
+
+// converts the legacy arguments by round-tripping them through a generic
+// value, so that every key, and the names listed in fields, become snake_case
+func (jsonrpc) encode(cmd *command) ([]byte, error) {
+	req := jsonrpcRequest{Version: "2.0", Method: snake(cmd.Method), Id: atomic.AddInt64(&requestId, 1)}
+	d, err := json.Marshal(cmd.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	var params interface{}
+	if err := unmarshalNumbers(d, &params); err != nil {
+		return nil, err
+	}
+	if m, ok := params.(map[string]interface{}); ok && len(m) > 0 {
+		req.Params = toSnake(m)
+	}
+	return json.Marshal(req)
+}
+
+// maps the result back onto the legacy names, reporting errors as the result
+// text and successes as "success" so that callers need not care
+func (self jsonrpc) decode(r io.Reader, rc *command) error {
+	if rc.each != nil {
+		return self.stream(r, rc)
+	}
+	resp := jsonrpcResponse{}
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		rc.Result = resp.Error.result()
+		return nil
+	}
+	rc.Result = "success"
+	if len(resp.Result) == 0 {
+		return nil
+	}
+
+	var result interface{}
+	if err := unmarshalNumbers(resp.Result, &result); err != nil {
+		return err
+	}
+	d, err := json.Marshal(toLegacy(result))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(d, &rc.Arguments)
+}
+
+// decodes the result a torrent at a time for commands with a callback
+func (jsonrpc) stream(r io.Reader, rc *command) error {
+	dec := json.NewDecoder(r)
+	var failure *jsonrpcError
+	err := members(dec, func(key string) error {
+		switch key {
+		case "result":
+			return streamArguments(dec, rc, legacyRaw)
+		case "error":
+			return dec.Decode(&failure)
+		}
+		return dec.Decode(new(json.RawMessage))
+	})
+	if err != nil {
+		return err
+	} else if failure != nil {
+		rc.Result = failure.result()
+	} else {
+		rc.Result = "success"
+	}
+	return nil
+}
+
+func unmarshalNumbers(d []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(d))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// converts kebab-case and camelCase names to snake_case, treating runs of
+// capitals as a single word (eg. isUTP becomes is_utp)
+func snake(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if c == '-' {
+			b.WriteRune('_')
+			continue
+		} else if unicode.IsUpper(c) && i > 0 && r[i-1] != '-' {
+			lower := unicode.IsLower(r[i-1]) || unicode.IsDigit(r[i-1])
+			acronym := unicode.IsUpper(r[i-1]) && i+1 < len(r) && unicode.IsLower(r[i+1])
+			if lower || acronym {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+func toSnake(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			if k == "fields" {
+				e = snakeStrings(e)
+			} else if k == "ids" && e == "recently-active" {
+				e = snake("recently-active")
+			}
+			m[snake(k)] = toSnake(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range t {
+			t[i] = toSnake(e)
+		}
+	}
+	return v
+}
+
+func snakeStrings(v interface{}) interface{} {
+	if l, ok := v.([]interface{}); ok {
+		for i, e := range l {
+			if s, ok := e.(string); ok {
+				l[i] = snake(s)
+			}
+		}
+	}
+	return v
+}
+
+// restores legacy names, copying values whose snake_case name is shared by
+// more than one legacy name (eg. download-dir and downloadDir)
+func toLegacy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			if k == "torrents" {
+				e = untable(e)
+			}
+			e = toLegacy(e)
+			names, ok := legacyNames()[k]
+			if !ok {
+				m[k] = e
+			}
+			for _, n := range names {
+				m[n] = e
+			}
+		}
+		return m
+	case []interface{}:
+		for i, e := range t {
+			t[i] = toLegacy(e)
+		}
+	}
+	return v
+}
+
+var legacyOnce sync.Once
+var legacyKeys map[string][]string
+
+// builds the snake_case to legacy name table from the json tags of every
+// type reachable from the arguments, so new fields are picked up for free
+func legacyNames() map[string][]string {
+	legacyOnce.Do(func() {
+		legacyKeys = map[string][]string{}
+		seen := map[reflect.Type]bool{}
+		collectNames(reflect.TypeOf(arguments{}), seen)
+		collectNames(reflect.TypeOf(torrentWire{}), seen)
+	})
+	return legacyKeys
+}
+
+func collectNames(t reflect.Type, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Anonymous && name == "" {
+			collectNames(f.Type, seen)
+			continue
+		} else if name == "-" || f.PkgPath != "" {
+			continue
+		} else if name == "" {
+			name = f.Name
+		}
+		key := snake(name)
+		if !contains(legacyKeys[key], name) {
+			legacyKeys[key] = append(legacyKeys[key], name)
+		}
+		collectNames(f.Type, seen)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
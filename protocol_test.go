@@ -0,0 +1,154 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnake(t *testing.T) {
+	t.Parallel()
+
+	names := map[string]string{
+		"id":                   "id",
+		"isFinished":           "is_finished",
+		"torrent-set-location": "torrent_set_location",
+		"rpc-version-minimum":  "rpc_version_minimum",
+		"isUTP":                "is_utp",
+		"fromDht":              "from_dht",
+		"HTTPServer":           "http_server",
+		"recently-active":      "recently_active",
+		"seedRatioLimit":       "seed_ratio_limit",
+	}
+	for legacy, expect := range names {
+		if s := snake(legacy); s != expect {
+			t.Logf("expected %s to become %s, but got %s", legacy, expect, s)
+			t.Fail()
+		}
+	}
+}
+
+func TestLegacyNames(t *testing.T) {
+	t.Parallel()
+
+	if names := legacyNames()["is_finished"]; len(names) != 1 || names[0] != "isFinished" {
+		t.Logf("unexpected legacy names: %v", names)
+		t.FailNow()
+	}
+	if names := legacyNames()["rpc_version"]; len(names) != 1 || names[0] != "rpc-version" {
+		t.Logf("unexpected legacy names: %v", names)
+		t.FailNow()
+	}
+}
+
+func TestJSONRPCEncode(t *testing.T) {
+	t.Parallel()
+
+	d, err := jsonrpc{}.encode(&command{Method: "torrent-set-location", Arguments: arguments{Ids: []int{1, 2}, Fields: []string{"isFinished"}, Location: movepath, Move: true}})
+	if err != nil {
+		t.FailNow()
+	}
+
+	var req map[string]interface{}
+	json.Unmarshal(d, &req)
+	params, _ := req["params"].(map[string]interface{})
+	expect := map[string]interface{}{"ids": []interface{}{1.0, 2.0}, "fields": []interface{}{"is_finished"}, "location": movepath, "move": true}
+	if req["jsonrpc"] != "2.0" || req["method"] != "torrent_set_location" || req["id"] == nil || !reflect.DeepEqual(params, expect) {
+		t.Logf("unexpected request: %s", d)
+		t.FailNow()
+	}
+
+	// verify empty arguments are omitted
+	d, _ = jsonrpc{}.encode(&command{Method: "torrent-start-now"})
+	if bytes.Contains(d, []byte("params")) {
+		t.Logf("unexpected params: %s", d)
+		t.FailNow()
+	}
+}
+
+func TestJSONRPCDecode(t *testing.T) {
+	t.Parallel()
+
+	// success is mapped onto the legacy names
+	rc := &command{}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","result":{"torrents":[{"id":3,"is_finished":true}]},"id":1}`), rc)
+	if err != nil || rc.Result != "success" || len(rc.Arguments.Torrents) != 1 || rc.Arguments.Torrents[0].Id != 3 || !rc.Arguments.Torrents[0].Finished {
+		t.Logf("error (%v) or unexpected command: %+v", err, rc)
+		t.FailNow()
+	}
+
+	// errors become the result text, preferring the detailed error string
+	rc = &command{}
+	jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`), rc)
+	if rc.Result != "Method not found" {
+		t.FailNow()
+	}
+	rc = &command{}
+	jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","error":{"code":1,"message":"Invalid params","data":{"error_string":"torrent not found"}},"id":1}`), rc)
+	if rc.Result != "torrent not found" {
+		t.FailNow()
+	}
+
+	// malformed responses are reported
+	if err := (jsonrpc{}).decode(strings.NewReader(`{`), &command{}); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestProtocolNegotiation(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server speaking json-rpc, besides the legacy session-get
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		var m map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&m)
+		w.WriteHeader(http.StatusOK)
+		if m["method"] == "session-get" && m["jsonrpc"] == nil {
+			w.Write([]byte(`{"result":"success","arguments":{"rpc-version":18}}`))
+		} else if m["method"] == "torrent_get" && m["jsonrpc"] == "2.0" {
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{"torrents":[{"id":1,"is_finished":false},{"id":2,"is_finished":true}]},"id":` + strconv.Itoa(int(m["id"].(float64))) + `}`))
+		} else if m["method"] == "torrent_remove" && m["jsonrpc"] == "2.0" {
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`))
+		} else {
+			t.Logf("unexpected request: %+v", m)
+			t.Fail()
+		}
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the api is unchanged over json-rpc
+	tr := Transmission{Port: port, Retry: RetryPolicy{Attempts: 1, Delay: time.Millisecond}}
+	l, err := tr.Finished()
+	if err != nil || len(l) != 1 || l[0].Id != 2 {
+		t.Logf("error (%v) or unexpected list: %+v", err, l)
+		t.FailNow()
+	}
+	if p, _ := tr.protocol(context.Background()); p != (jsonrpc{}) {
+		t.FailNow()
+	}
+
+	// verify errors carry the json-rpc message
+	err = tr.Remove(Select(l...)...)
+	if !errors.Is(err, ErrResult) || !strings.Contains(err.Error(), "Invalid params") {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
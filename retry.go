@@ -0,0 +1,93 @@
+package transmission
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const defaultAttempts = 3
+const defaultDelay = 2 * time.Second
+const defaultMaxDelay = 30 * time.Second
+
+var random = rand.Float64
+
+// controls how failed requests are retried, where zero values fall back to
+// three attempts with a delay starting at two seconds and doubling each time
+type RetryPolicy struct {
+
+	// total number of requests made before giving up
+	Attempts int
+
+	// wait before the first retry, doubled for each subsequent retry up to
+	// the maximum
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// fraction of each delay, between 0 and 1, that is randomly shortened so
+	// that many clients do not retry in lockstep
+	Jitter float64
+
+	// decides whether the *Error from a failed attempt is worth retrying,
+	// where nil retries every failure except certificate verification and tls
+	// alerts, which will not change by trying again
+	Retryable func(error) bool
+
+	// whether refreshing the session token on a 409 consumes an attempt,
+	// otherwise the first refresh per call is free
+	CountConflicts bool
+}
+
+// returns a copy of the policy with defaults applied
+func (self RetryPolicy) normalize() RetryPolicy {
+	if self.Attempts <= 0 {
+		self.Attempts = defaultAttempts
+	}
+	if self.Delay <= 0 {
+		self.Delay = defaultDelay
+	}
+	if self.MaxDelay <= 0 {
+		self.MaxDelay = defaultMaxDelay
+	}
+	if self.MaxDelay < self.Delay {
+		self.MaxDelay = self.Delay
+	}
+	if self.Jitter < 0 {
+		self.Jitter = 0
+	} else if self.Jitter > 1 {
+		self.Jitter = 1
+	}
+	return self
+}
+
+func (self RetryPolicy) retryable(err error) bool {
+	if self.Retryable != nil {
+		return self.Retryable(err)
+	}
+	return !permanent(err)
+}
+
+// whether the failure is a tls handshake that was refused on either side
+func permanent(err error) bool {
+	var verification *tls.CertificateVerificationError
+	var alert tls.AlertError
+	var authority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.As(err, &verification) || errors.As(err, &alert) || errors.As(err, &authority) || errors.As(err, &invalid) || errors.As(err, &hostname)
+}
+
+// computes the wait after the supplied attempt (starting from 1), with
+// exponential growth capped at MaxDelay and jitter applied last
+func (self RetryPolicy) delay(attempt int) time.Duration {
+	d := self.Delay
+	for i := 1; i < attempt && d < self.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > self.MaxDelay {
+		d = self.MaxDelay
+	}
+	return d - time.Duration(self.Jitter*random()*float64(d))
+}
@@ -0,0 +1,119 @@
+package transmission
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyNormalize(t *testing.T) {
+	t.Parallel()
+
+	p := RetryPolicy{}.normalize()
+	if p.Attempts != defaultAttempts || p.Delay != defaultDelay || p.MaxDelay != defaultMaxDelay {
+		t.Logf("unexpected defaults: %+v", p)
+		t.FailNow()
+	}
+
+	p = RetryPolicy{Delay: time.Minute, Jitter: 2}.normalize()
+	if p.MaxDelay != time.Minute || p.Jitter != 1 {
+		t.Logf("unexpected normalized policy: %+v", p)
+		t.FailNow()
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	defer func(r func() float64) { random = r }(random)
+	random = func() float64 { return 0.5 }
+
+	p := RetryPolicy{Delay: time.Second, MaxDelay: 5 * time.Second}.normalize()
+	for attempt, expect := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if d := p.delay(attempt + 1); d != expect {
+			t.Logf("attempt %d expected %s, but got %s", attempt+1, expect, d)
+			t.Fail()
+		}
+	}
+
+	// verify jitter shortens the delay
+	p.Jitter = 0.5
+	if d := p.delay(2); d != 1500*time.Millisecond {
+		t.Logf("expected jittered delay of 1.5s, but got %s", d)
+		t.FailNow()
+	}
+}
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server that always fails, counting requests
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(getTorrentsFail)
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the token refresh is free by default
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 5, Delay: time.Millisecond}}
+	if _, err := tr.Get(); err == nil || atomic.LoadInt32(&calls) != 6 {
+		t.Logf("expected 6 requests, but got %d", atomic.LoadInt32(&calls))
+		t.FailNow()
+	}
+
+	// verify non-retryable failures stop immediately
+	atomic.StoreInt32(&calls, 0)
+	tr.Retry.Retryable = func(error) bool { return false }
+	if _, err := tr.Get(); err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Logf("expected 1 request, but got %d", atomic.LoadInt32(&calls))
+		t.FailNow()
+	}
+
+	// verify a counted token refresh consumes the only attempt
+	atomic.StoreInt32(&calls, 0)
+	tr.Token = ""
+	tr.Retry = RetryPolicy{Attempts: 1, CountConflicts: true}
+	if _, err := tr.Get(); err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Logf("expected 1 request, but got %d", atomic.LoadInt32(&calls))
+		t.FailNow()
+	}
+}
+
+func TestRetryablePermanent(t *testing.T) {
+	t.Parallel()
+
+	// verify certificate failures are final by default, unless overridden
+	var policy RetryPolicy
+	for _, err := range []error{
+		&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}},
+		fmt.Errorf("post: %w", x509.HostnameError{}),
+		tls.AlertError(116),
+	} {
+		if policy.retryable(&Error{Err: err, transport: true}) {
+			t.Logf("retried permanent failure: %v", err)
+			t.Fail()
+		}
+	}
+	if !policy.retryable(&Error{Status: http.StatusInternalServerError}) {
+		t.FailNow()
+	}
+	policy.Retryable = func(error) bool { return true }
+	if !policy.retryable(&Error{Err: tls.AlertError(116), transport: true}) {
+		t.FailNow()
+	}
+}
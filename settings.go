@@ -0,0 +1,59 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// values for the seed ratio and idle modes of torrent-set and torrent-get
+const (
+	SeedModeSession   = 0
+	SeedModeTorrent   = 1
+	SeedModeUnlimited = 2
+)
+
+// the torrent-set options, where only the non-nil members are sent so every
+// other setting is left as it was; limits are in KB/s and the seed idle
+// limit in minutes
+// @link: https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+type TorrentSettings struct {
+	DownloadLimit       *int      `json:"downloadLimit,omitempty"`
+	DownloadLimited     *bool     `json:"downloadLimited,omitempty"`
+	UploadLimit         *int      `json:"uploadLimit,omitempty"`
+	UploadLimited       *bool     `json:"uploadLimited,omitempty"`
+	HonorsSessionLimits *bool     `json:"honorsSessionLimits,omitempty"`
+	BandwidthPriority   *Priority `json:"bandwidthPriority,omitempty"`
+	SeedRatioLimit      *float64  `json:"seedRatioLimit,omitempty"`
+	SeedRatioMode       *int      `json:"seedRatioMode,omitempty"`
+	SeedIdleLimit       *int      `json:"seedIdleLimit,omitempty"`
+	SeedIdleMode        *int      `json:"seedIdleMode,omitempty"`
+	PeerLimit           *int      `json:"peer-limit,omitempty"`
+	QueuePosition       *int      `json:"queuePosition,omitempty"`
+
+	// file indices, where an empty list is never sent since the daemon would
+	// apply it to every file
+	FilesWanted         []int `json:"files-wanted,omitempty"`
+	FilesUnwanted       []int `json:"files-unwanted,omitempty"`
+	FilesPriorityHigh   []int `json:"priority-high,omitempty"`
+	FilesPriorityNormal []int `json:"priority-normal,omitempty"`
+	FilesPriorityLow    []int `json:"priority-low,omitempty"`
+}
+
+// applies the settings to the supplied torrents, doing nothing when there
+// are no torrents or no settings
+func (self *Transmission) Set(settings TorrentSettings, ids ...TorrentID) error {
+	return self.SetContext(context.Background(), settings, ids...)
+}
+
+func (self *Transmission) SetContext(ctx context.Context, settings TorrentSettings, ids ...TorrentID) error {
+	if d, err := json.Marshal(settings); err != nil || len(ids) == 0 || string(d) == "{}" {
+		return err
+	}
+	wire, err := wireIds(ids)
+	if err != nil {
+		return err
+	}
+	cmd := &command{Method: "torrent-set", Arguments: arguments{Ids: wire, TorrentSettings: &settings}}
+	_, err = self.send(ctx, cmd)
+	return err
+}
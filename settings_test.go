@@ -0,0 +1,75 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server verifying only the changed keys are sent
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		var c struct {
+			Method    string
+			Arguments map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&c)
+		expected := map[string]interface{}{"ids": []interface{}{float64(4)}, "uploadLimit": float64(0), "uploadLimited": true, "bandwidthPriority": float64(1), "peer-limit": float64(20), "seedRatioMode": float64(2)}
+		if c.Method != "torrent-set" || !reflect.DeepEqual(c.Arguments, expected) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify zero values are sent when set
+	limit, limited, priority, peers, mode := 0, true, PriorityHigh, 20, SeedModeUnlimited
+	settings := TorrentSettings{UploadLimit: &limit, UploadLimited: &limited, BandwidthPriority: &priority, PeerLimit: &peers, SeedRatioMode: &mode}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if err := tr.Set(settings, ID(4)); err != nil {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+
+	// verify nothing is sent without ids or settings
+	if tr.Set(settings) != nil || tr.Set(TorrentSettings{}, ID(4)) != nil || atomic.LoadInt32(&calls) != 1 {
+		t.FailNow()
+	}
+}
+
+func TestSetJSONRPC(t *testing.T) {
+	t.Parallel()
+
+	// verify the settings are sent in snake_case
+	limit := 100
+	d, err := jsonrpc{}.encode(&command{Method: "torrent-set", Arguments: arguments{Ids: []interface{}{1}, TorrentSettings: &TorrentSettings{DownloadLimit: &limit, PeerLimit: &limit}}})
+	if err != nil {
+		t.FailNow()
+	}
+	var req struct{ Params map[string]interface{} }
+	json.Unmarshal(d, &req)
+	if req.Params["download_limit"] != float64(100) || req.Params["peer_limit"] != float64(100) || len(req.Params) != 3 {
+		t.Logf("unexpected params: %s", d)
+		t.FailNow()
+	}
+}
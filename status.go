@@ -0,0 +1,80 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// the activity state of a torrent
+type Status int
+
+const (
+	StatusStopped Status = iota
+	StatusCheckWait
+	StatusChecking
+	StatusDownloadWait
+	StatusDownloading
+	StatusSeedWait
+	StatusSeeding
+)
+
+var statusNames = []string{"stopped", "check-wait", "checking", "download-wait", "downloading", "seed-wait", "seeding"}
+
+func (self Status) String() string {
+	if self < 0 || int(self) >= len(statusNames) {
+		return "status(" + strconv.Itoa(int(self)) + ")"
+	}
+	return statusNames[self]
+}
+
+// transferring data or verifying it, rather than stopped or waiting in a queue
+func (self Status) IsActive() bool {
+	return self == StatusChecking || self == StatusDownloading || self == StatusSeeding
+}
+
+func (self Status) IsSeeding() bool {
+	return self == StatusSeeding
+}
+
+func (self Status) IsDownloading() bool {
+	return self == StatusDownloading
+}
+
+func (self Status) IsChecking() bool {
+	return self == StatusChecking
+}
+
+func (self Status) IsStopped() bool {
+	return self == StatusStopped
+}
+
+// waiting for a free slot to check, download or seed
+func (self Status) IsQueued() bool {
+	return self == StatusCheckWait || self == StatusDownloadWait || self == StatusSeedWait
+}
+
+// marshals as the number the daemon uses
+func (self Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(self))
+}
+
+// accepts both the number the daemon uses and the names from String
+func (self *Status) UnmarshalJSON(d []byte) error {
+	var name string
+	if err := json.Unmarshal(d, &name); err != nil {
+		var n int
+		if err := json.Unmarshal(d, &n); err != nil {
+			return err
+		}
+		*self = Status(n)
+		return nil
+	}
+	for i, s := range statusNames {
+		if s == name {
+			*self = Status(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown torrent status %q", name)
+}
@@ -0,0 +1,48 @@
+package transmission
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStatusString(t *testing.T) {
+	t.Parallel()
+
+	if StatusStopped.String() != "stopped" || StatusDownloadWait.String() != "download-wait" || StatusSeeding.String() != "seeding" || Status(9).String() != "status(9)" {
+		t.FailNow()
+	}
+}
+
+func TestStatusHelpers(t *testing.T) {
+	t.Parallel()
+
+	for s := StatusStopped; s <= StatusSeeding; s++ {
+		active := s == StatusChecking || s == StatusDownloading || s == StatusSeeding
+		queued := s == StatusCheckWait || s == StatusDownloadWait || s == StatusSeedWait
+		if s.IsActive() != active || s.IsQueued() != queued || s.IsSeeding() != (s == StatusSeeding) || s.IsDownloading() != (s == StatusDownloading) || s.IsChecking() != (s == StatusChecking) || s.IsStopped() != (s == StatusStopped) {
+			t.Logf("unexpected helpers for %s", s)
+			t.Fail()
+		}
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	t.Parallel()
+
+	var s Status
+	if err := json.Unmarshal([]byte(`4`), &s); err != nil || s != StatusDownloading {
+		t.FailNow()
+	}
+	if err := json.Unmarshal([]byte(`"seed-wait"`), &s); err != nil || s != StatusSeedWait {
+		t.FailNow()
+	}
+	if err := json.Unmarshal([]byte(`"paused"`), &s); err == nil {
+		t.FailNow()
+	}
+	if err := json.Unmarshal([]byte(`true`), &s); err == nil {
+		t.FailNow()
+	}
+	if d, err := json.Marshal(StatusSeeding); err != nil || string(d) != "6" {
+		t.FailNow()
+	}
+}
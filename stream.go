@@ -0,0 +1,137 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// passes each torrent to fn as soon as it is decoded, instead of holding the
+// whole list, so memory stays flat on very large instances; an error from fn
+// stops the stream and is returned as is, and since torrents may already have
+// been seen a stream is never retried once decoding has begun
+//
+// the default client's timeout is not applied to streams, so bound them with
+// the context of StreamContext, whereas the Timeout of a supplied Client
+// covers the whole stream, including the time spent in fn
+func (self *Transmission) Stream(fn func(Torrent) error, fields ...Field) error {
+	return self.StreamContext(context.Background(), fn, fields...)
+}
+
+func (self *Transmission) StreamContext(ctx context.Context, fn func(Torrent) error, fields ...Field) error {
+	cmd, err := self.torrentCommand(ctx, nil, fields...)
+	if err != nil {
+		return err
+	}
+	cmd.each = fn
+	_, err = self.send(ctx, cmd)
+	return err
+}
+
+// walks the members of the object the decoder is positioned at, leaving fn
+// to consume each value, and accepts null as an empty object
+func members(dec *json.Decoder, fn func(key string) error) error {
+	t, err := dec.Token()
+	if err != nil || t == nil {
+		return err
+	} else if t != json.Delim('{') {
+		return fmt.Errorf("%w: expected an object, found %v", ErrMalformed, t)
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(t.(string)); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// decodes the members of the arguments object, handing each torrent to the
+// commands callback and every other member to the arguments, where convert
+// translates the raw values of a protocol into the legacy form, or is nil
+// when they already are
+func streamArguments(dec *json.Decoder, rc *command, convert func(json.RawMessage) (json.RawMessage, error)) error {
+	rest := map[string]json.RawMessage{}
+	err := members(dec, func(key string) error {
+		if key == "torrents" {
+			return streamTorrents(dec, rc, convert)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		rest[key] = raw
+		return nil
+	})
+	if err != nil || len(rest) == 0 {
+		return err
+	}
+	d, err := json.Marshal(rest)
+	if err == nil && convert != nil {
+		d, err = convert(d)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(d, &rc.Arguments)
+}
+
+// decodes the torrents array one element at a time, decoding table rows by
+// the columns of the header row
+func streamTorrents(dec *json.Decoder, rc *command, convert func(json.RawMessage) (json.RawMessage, error)) error {
+	t, err := dec.Token()
+	if err != nil || t == nil {
+		return err
+	} else if t != json.Delim('[') {
+		return fmt.Errorf("%w: expected torrents, found %v", ErrMalformed, t)
+	}
+
+	var table columns
+	for first := true; dec.More(); first = false {
+		var torrent Torrent
+		if table != nil {
+			if torrent, err = table.decode(dec, convert); err != nil {
+				return err
+			}
+		} else {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			if first && len(raw) > 0 && raw[0] == '[' {
+				var header []string
+				if err := json.Unmarshal(raw, &header); err != nil {
+					return err
+				}
+				table = tableColumns(header, convert != nil)
+				continue
+			} else if convert != nil {
+				if raw, err = convert(raw); err != nil {
+					return err
+				}
+			}
+			if err := json.Unmarshal(raw, &torrent); err != nil {
+				return err
+			}
+		}
+		if err := rc.each(torrent); err != nil {
+			rc.stop = err
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// restores the legacy names of a json-rpc value
+func legacyRaw(raw json.RawMessage) (json.RawMessage, error) {
+	var v interface{}
+	if err := unmarshalNumbers(raw, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(toLegacy(v))
+}
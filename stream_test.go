@@ -0,0 +1,328 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func streamServer(t testing.TB, body string) (*httptest.Server, int) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+	return ts, port
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	bodies := map[string]string{
+		"objects": `{"arguments":{"torrents":[{"id":1,"name":"a"},{"id":2,"name":"b"}]},"result":"success"}`,
+		"table":   `{"arguments":{"torrents":[["id","name"],[1,"a"],[2,"b"]]},"result":"success","tag":1}`,
+	}
+	for name, body := range bodies {
+		ts, port := streamServer(t, body)
+		defer ts.Close()
+
+		// verify each torrent is passed along in order
+		tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+		var names []string
+		err := tr.Stream(func(torrent Torrent) error {
+			names = append(names, strconv.Itoa(torrent.Id)+torrent.Name)
+			return nil
+		}, FieldName)
+		if err != nil || strings.Join(names, ",") != "1a,2b" {
+			t.Logf("%s: error (%v) or unexpected torrents: %v", name, err, names)
+			t.FailNow()
+		}
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	t.Parallel()
+
+	ts, port := streamServer(t, `{"arguments":{"torrents":[{"id":1},{"id":2},{"id":3}]},"result":"success"}`)
+	defer ts.Close()
+
+	// verify the callback error is returned untouched after the first torrent
+	stop := errors.New("enough")
+	var calls int
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	err := tr.Stream(func(Torrent) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Logf("error (%v) or unexpected calls: %d", err, calls)
+		t.FailNow()
+	}
+}
+
+func TestStreamResult(t *testing.T) {
+	t.Parallel()
+
+	ts, port := streamServer(t, `{"arguments":{"torrents":[{"id":1},{"id":2}]},"result":"no such torrent"}`)
+	defer ts.Close()
+
+	// verify unsuccessful results are reported without retrying, since the
+	// torrents have already been passed along
+	var calls int
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 3, Delay: time.Millisecond}}
+	err := tr.Stream(func(Torrent) error {
+		calls++
+		return nil
+	})
+	var e *Error
+	if !errors.Is(err, ErrResult) || !errors.As(err, &e) || e.Attempts != 1 || calls != 2 {
+		t.Logf("error (%v) or unexpected calls: %d", err, calls)
+		t.FailNow()
+	}
+}
+
+func TestStreamJSONRPC(t *testing.T) {
+	t.Parallel()
+
+	// verify snake_case objects and tables stream with legacy names
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","result":{"torrents":[{"id":1,"download_dir":"/a"}],"removed":[4]},"id":1}`,
+		`{"jsonrpc":"2.0","result":{"torrents":[["id","download_dir"],[1,"/a"]],"removed":[4]},"id":1}`,
+	} {
+		var dirs []string
+		rc := &command{each: func(torrent Torrent) error {
+			dirs = append(dirs, torrent.DownloadDir)
+			return nil
+		}}
+		err := jsonrpc{}.decode(strings.NewReader(body), rc)
+		if err != nil || rc.Result != "success" || len(dirs) != 1 || dirs[0] != "/a" || len(rc.Arguments.Removed) != 1 {
+			t.Logf("error (%v) or unexpected result %q: %v %+v", err, rc.Result, dirs, rc.Arguments)
+			t.FailNow()
+		}
+	}
+
+	// verify errors become the result
+	rc := &command{each: func(Torrent) error { return nil }}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":{"error_string":"bad ids"}},"id":1}`), rc)
+	if err != nil || rc.Result != "bad ids" {
+		t.FailNow()
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	t.Parallel()
+
+	ts, port := streamServer(t, `{"arguments":{"torrents":[{"id":1},}]},"result":"success"}`)
+	defer ts.Close()
+
+	// verify invalid responses are reported rather than ignored, keeping the
+	// underlying syntax error
+	var syntax *json.SyntaxError
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 1}}
+	if _, err := tr.GetFields(); !errors.Is(err, ErrMalformed) || !errors.As(err, &syntax) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+
+	// verify streams report them too, after passing what did arrive
+	var calls int
+	tr.Retry.Attempts = 3
+	err := tr.Stream(func(Torrent) error {
+		calls++
+		return nil
+	})
+	var e *Error
+	if !errors.Is(err, ErrMalformed) || !errors.As(err, &e) || e.Attempts != 1 || calls != 1 {
+		t.Logf("error (%v) or unexpected calls: %d", err, calls)
+		t.FailNow()
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	t.Parallel()
+
+	ts, port := streamServer(t, `{"arguments":{"torrents":[{"id":1},`)
+	defer ts.Close()
+
+	// verify a body cut short is not mistaken for malformed json
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Retry: RetryPolicy{Attempts: 1}}
+	if _, err := tr.GetFields(); !errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrMalformed) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestStreamInterrupted(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server which stalls after the first torrent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"arguments":{"torrents":[{"id":1},`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte(`{"id":2}]},"result":"success"}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify a client timeout is not mistaken for malformed json
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy, Client: &http.Client{Timeout: 100 * time.Millisecond}}
+	err := tr.Stream(func(Torrent) error { return nil })
+	if err == nil || errors.Is(err, ErrMalformed) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+
+	// verify cancelling the context is reported as such
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tr.Client = nil
+	err = tr.StreamContext(ctx, func(Torrent) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrMalformed) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
+
+// records whether each request carried a deadline from the client timeout
+type deadlineTransport struct {
+	deadlines []bool
+}
+
+func (self *deadlineTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	_, ok := r.Context().Deadline()
+	self.deadlines = append(self.deadlines, ok)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"arguments":{"torrents":[]},"result":"success"}`)), Request: r}, nil
+}
+
+func TestStreamTimeout(t *testing.T) {
+	t.Parallel()
+
+	// verify the default timeout applies to requests but not to streams
+	rt := &deadlineTransport{}
+	tr := Transmission{Protocol: ProtocolLegacy, Transport: rt}
+	if _, err := tr.GetFields(); err != nil {
+		t.FailNow()
+	}
+	if err := tr.Stream(func(Torrent) error { return nil }); err != nil {
+		t.FailNow()
+	}
+	if len(rt.deadlines) != 2 || !rt.deadlines[0] || rt.deadlines[1] {
+		t.Logf("unexpected deadlines: %v", rt.deadlines)
+		t.FailNow()
+	}
+}
+
+// synthetic torrent-get responses for 50k torrents
+var payloads struct {
+	sync.Once
+	objects, table, snake []byte
+}
+
+func syntheticPayloads() {
+	payloads.Do(func() {
+		var objects, table, snake bytes.Buffer
+		objects.WriteString(`{"arguments":{"torrents":[`)
+		table.WriteString(`{"arguments":{"torrents":[["id","name","hashString","status","percentDone","rateDownload","rateUpload","totalSize","addedDate","isFinished"]`)
+		snake.WriteString(`{"jsonrpc":"2.0","result":{"torrents":[`)
+		for i := 1; i <= 50000; i++ {
+			if i > 1 {
+				objects.WriteByte(',')
+				snake.WriteByte(',')
+			}
+			hash := fmt.Sprintf("%040x", i)
+			fmt.Fprintf(&objects, `{"id":%d,"name":"torrent %d","hashString":"%s","status":4,"percentDone":0.5,"rateDownload":1024,"rateUpload":512,"totalSize":1073741824,"addedDate":1700000000,"isFinished":false}`, i, i, hash)
+			fmt.Fprintf(&table, `,[%d,"torrent %d","%s",4,0.5,1024,512,1073741824,1700000000,false]`, i, i, hash)
+			fmt.Fprintf(&snake, `{"id":%d,"name":"torrent %d","hash_string":"%s","status":4,"percent_done":0.5,"rate_download":1024,"rate_upload":512,"total_size":1073741824,"added_date":1700000000,"is_finished":false}`, i, i, hash)
+		}
+		objects.WriteString(`]},"result":"success"}`)
+		table.WriteString(`]},"result":"success"}`)
+		snake.WriteString(`]},"id":1}`)
+		payloads.objects, payloads.table, payloads.snake = objects.Bytes(), table.Bytes(), snake.Bytes()
+	})
+}
+
+func benchmarkDecode(b *testing.B, proto protocol, payload []byte, stream bool) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var count int
+		rc := &command{}
+		if stream {
+			rc.each = func(Torrent) error {
+				count++
+				return nil
+			}
+		}
+		if err := proto.decode(bytes.NewReader(payload), rc); err != nil {
+			b.Fatal(err)
+		} else if !stream {
+			count = len(rc.Arguments.Torrents)
+		}
+		if count != 50000 {
+			b.Fatalf("decoded %d torrents", count)
+		}
+	}
+}
+
+func BenchmarkDecodeObjects(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, legacy{}, payloads.objects, false)
+}
+
+func BenchmarkStreamObjects(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, legacy{}, payloads.objects, true)
+}
+
+func BenchmarkDecodeTable(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, legacy{}, payloads.table, false)
+}
+
+func BenchmarkStreamTable(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, legacy{}, payloads.table, true)
+}
+
+func BenchmarkDecodeJSONRPC(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, jsonrpc{}, payloads.snake, false)
+}
+
+func BenchmarkStreamJSONRPC(b *testing.B) {
+	syntheticPayloads()
+	benchmarkDecode(b, jsonrpc{}, payloads.snake, true)
+}
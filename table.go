@@ -0,0 +1,189 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// the first rpc-version to answer torrent-get with a header row and arrays
+const tableVersion = 16
+
+// whether torrent-get may ask for the table format, consulting the daemon
+// only when negotiating the protocol would have done so regardless
+func (self *Transmission) tables(ctx context.Context) (bool, error) {
+	self.RLock()
+	v, selected := self.version, self.Protocol
+	self.RUnlock()
+	if v != nil {
+		return v.RPC >= tableVersion, nil
+	} else if selected != ProtocolAuto {
+		return false, nil
+	}
+	version, err := self.VersionContext(ctx)
+	return err == nil && version.RPC >= tableVersion, err
+}
+
+// the torrents of a torrent-get, accepting both the object format and the
+// table format, where the first row names the columns of every other row
+type torrentList []Torrent
+
+func (self *torrentList) UnmarshalJSON(d []byte) error {
+	if !isTable(d) {
+		return json.Unmarshal(d, (*[]Torrent)(self))
+	}
+	dec := json.NewDecoder(bytes.NewReader(d))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	var header []string
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	columns := tableColumns(header, false)
+	list := torrentList{}
+	for dec.More() {
+		torrent, err := columns.decode(dec, nil)
+		if err != nil {
+			return err
+		}
+		list = append(list, torrent)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	*self = list
+	return nil
+}
+
+// whether the torrents start with a header row rather than an object
+func isTable(d []byte) bool {
+	d = bytes.TrimSpace(d)
+	return len(d) > 1 && d[0] == '[' && bytes.HasPrefix(bytes.TrimSpace(d[1:]), []byte("["))
+}
+
+// where a table column decodes to, within either the model or its wire
+// members, where a nil column is not part of the model and is skipped
+type column struct {
+	wire  bool
+	index int
+}
+
+type columns []*column
+
+var columnOnce sync.Once
+var columnIndex map[string]*column
+
+// maps each json name of the model, and of the wire members which take
+// precedence over it, to the field it decodes into
+func columnNames() map[string]*column {
+	columnOnce.Do(func() {
+		columnIndex = map[string]*column{}
+		for _, c := range []struct {
+			t    reflect.Type
+			wire bool
+		}{{reflect.TypeOf(torrentWire{}), true}, {reflect.TypeOf(Torrent{}), false}} {
+			for i := 0; i < c.t.NumField(); i++ {
+				name := strings.Split(c.t.Field(i).Tag.Get("json"), ",")[0]
+				if _, ok := columnIndex[name]; !ok && name != "" && name != "-" {
+					columnIndex[name] = &column{wire: c.wire, index: i}
+				}
+			}
+		}
+	})
+	return columnIndex
+}
+
+// resolves the header row, translating snake_case names when the table came
+// over json-rpc
+func tableColumns(header []string, snakeCase bool) columns {
+	index := columnNames()
+	list := make(columns, len(header))
+	for i, name := range header {
+		if !snakeCase {
+			list[i] = index[name]
+			continue
+		}
+		for _, n := range legacyNames()[name] {
+			if c, ok := index[n]; ok {
+				list[i] = c
+				break
+			}
+		}
+	}
+	return list
+}
+
+// decodes the row the decoder is positioned at straight into a torrent, with
+// convert translating each cell first when it is not already in legacy form
+func (self columns) decode(dec *json.Decoder, convert func(json.RawMessage) (json.RawMessage, error)) (Torrent, error) {
+	var torrent Torrent
+	var wire torrentWire
+	if t, err := dec.Token(); err != nil {
+		return torrent, err
+	} else if t != json.Delim('[') {
+		return torrent, fmt.Errorf("%w: expected a torrent table row, found %v", ErrMalformed, t)
+	}
+
+	model, members := reflect.ValueOf(&torrent).Elem(), reflect.ValueOf(&wire).Elem()
+	var skip json.RawMessage
+	var i int
+	for ; dec.More(); i++ {
+		var target interface{} = &skip
+		if i < len(self) && self[i] != nil && self[i].wire {
+			target = members.Field(self[i].index).Addr().Interface()
+		} else if i < len(self) && self[i] != nil {
+			target = model.Field(self[i].index).Addr().Interface()
+		}
+		if convert == nil {
+			if err := dec.Decode(target); err != nil {
+				return torrent, err
+			}
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return torrent, err
+		} else if raw, err = convert(raw); err != nil {
+			return torrent, err
+		} else if err := json.Unmarshal(raw, target); err != nil {
+			return torrent, err
+		}
+	}
+	if i != len(self) {
+		return torrent, fmt.Errorf("%w: torrent table row has %d columns, expected %d", ErrMalformed, i, len(self))
+	} else if _, err := dec.Token(); err != nil {
+		return torrent, err
+	}
+	torrent.convert(&wire)
+	return torrent, nil
+}
+
+// converts a generic table into objects, so their keys can be translated
+// like any other, and returns anything else untouched
+func untable(v interface{}) interface{} {
+	rows, ok := v.([]interface{})
+	if !ok || len(rows) == 0 {
+		return v
+	}
+	header, ok := rows[0].([]interface{})
+	if !ok {
+		return v
+	}
+	objects := make([]interface{}, 0, len(rows)-1)
+	for _, r := range rows[1:] {
+		cells, _ := r.([]interface{})
+		m := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			if s, ok := name.(string); ok && i < len(cells) {
+				m[s] = cells[i]
+			}
+		}
+		objects = append(objects, m)
+	}
+	return objects
+}
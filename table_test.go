@@ -0,0 +1,105 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server which only answers tables when asked
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		w.WriteHeader(http.StatusOK)
+		if c.Method == "session-get" {
+			w.Write(sessionGetSuccess)
+		} else if c.Arguments.Format != "table" {
+			t.Logf("unexpected format: %q", c.Arguments.Format)
+			t.Fail()
+		} else {
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[["id","name","addedDate","isFinished"],[1,"a",1700000000,true],[2,"b",0,false]]}}`))
+		}
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify rows decode like objects
+	tr := Transmission{Port: port}
+	list, err := tr.GetFields(FieldName, FieldAddedDate, FieldFinished)
+	if err != nil || len(list) != 2 {
+		t.Logf("error (%v) or unexpected list: %+v", err, list)
+		t.FailNow()
+	}
+	if list[0].Id != 1 || list[0].Name != "a" || !list[0].Finished || !list[0].AddedDate.Equal(time.Unix(1700000000, 0)) || list[1].Name != "b" || !list[1].AddedDate.IsZero() {
+		t.Logf("unexpected list: %+v", list)
+		t.FailNow()
+	}
+}
+
+func TestTableUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// verify old daemons and fixed protocols without a known version get objects
+	old := &Transmission{version: &Version{RPC: 15}}
+	fixed := &Transmission{Protocol: ProtocolLegacy}
+	for _, tr := range []*Transmission{old, fixed} {
+		if table, err := tr.tables(context.Background()); err != nil || table {
+			t.FailNow()
+		}
+	}
+}
+
+func TestTableDecode(t *testing.T) {
+	t.Parallel()
+
+	// verify mismatched rows are rejected
+	var list torrentList
+	for _, body := range []string{`[["id","name"],[1]]`, `[["id","name"],[1,"a",2]]`, `[["id","name"],{"id":1}]`} {
+		if err := json.Unmarshal([]byte(body), &list); !errors.Is(err, ErrMalformed) {
+			t.Logf("%s: unexpected error: %v", body, err)
+			t.FailNow()
+		}
+	}
+
+	// verify unknown columns are skipped and merged fields match objects
+	table := `[["id","future","files","wanted","eta"],[1,{"a":[1]},[{"name":"a","length":2}],[0],60]]`
+	objects := `[{"id":1,"future":{"a":[1]},"files":[{"name":"a","length":2}],"wanted":[0],"eta":60}]`
+	var expect torrentList
+	if err := json.Unmarshal([]byte(table), &list); err != nil {
+		t.FailNow()
+	} else if err := json.Unmarshal([]byte(objects), &expect); err != nil || !reflect.DeepEqual(list, expect) {
+		t.Logf("error (%v) or unexpected torrents: %+v, expected %+v", err, list, expect)
+		t.FailNow()
+	}
+
+	// verify snake_case headers reach the model over json-rpc
+	rc := &command{}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","result":{"torrents":[["id","download_dir","percent_done"],[7,"/tmp",0.5]]},"id":1}`), rc)
+	if err != nil || len(rc.Arguments.Torrents) != 1 {
+		t.Logf("error (%v) or unexpected torrents: %+v", err, rc.Arguments.Torrents)
+		t.FailNow()
+	}
+	torrent := rc.Arguments.Torrents[0]
+	if torrent.Id != 7 || torrent.DownloadDir != "/tmp" || torrent.PercentDone != 0.5 {
+		t.Logf("unexpected torrent: %+v", torrent)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,253 @@
+package transmission
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// eta values reported when the daemon cannot estimate a completion time
+const (
+	ETANotAvailable = -1 * time.Second
+	ETAUnknown      = -2 * time.Second
+)
+
+// a torrent as described by torrent-get, where only the requested fields
+// are populated; dates are zero when unset and rates are in bytes per second
+// @link: https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+type Torrent struct {
+	Id         int      `json:"id,omitempty"`
+	HashString string   `json:"hashString,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Comment    string   `json:"comment,omitempty"`
+	Creator    string   `json:"creator,omitempty"`
+	MagnetLink string   `json:"magnetLink,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	MimeType   string   `json:"primary-mime-type,omitempty"`
+
+	Status      Status `json:"status,omitempty"`
+	Finished    bool   `json:"isFinished,omitempty"`
+	Private     bool   `json:"isPrivate,omitempty"`
+	Stalled     bool   `json:"isStalled,omitempty"`
+	Error       int    `json:"error,omitempty"`
+	ErrorString string `json:"errorString,omitempty"`
+
+	DownloadDir  string   `json:"downloadDir,omitempty"`
+	TorrentFile  string   `json:"torrentFile,omitempty"`
+	FileCount    int      `json:"file-count,omitempty"`
+	PieceCount   int      `json:"pieceCount,omitempty"`
+	PieceSize    int64    `json:"pieceSize,omitempty"`
+	Pieces       PieceMap `json:"pieces,omitempty"`
+	TotalSize    int64    `json:"totalSize,omitempty"`
+	SizeWhenDone int64    `json:"sizeWhenDone,omitempty"`
+
+	LeftUntilDone    int64   `json:"leftUntilDone,omitempty"`
+	DesiredAvailable int64   `json:"desiredAvailable,omitempty"`
+	HaveUnchecked    int64   `json:"haveUnchecked,omitempty"`
+	HaveValid        int64   `json:"haveValid,omitempty"`
+	CorruptEver      int64   `json:"corruptEver,omitempty"`
+	DownloadedEver   int64   `json:"downloadedEver,omitempty"`
+	UploadedEver     int64   `json:"uploadedEver,omitempty"`
+	PercentComplete  float64 `json:"percentComplete,omitempty"`
+	PercentDone      float64 `json:"percentDone,omitempty"`
+	MetadataPercent  float64 `json:"metadataPercentComplete,omitempty"`
+	RecheckProgress  float64 `json:"recheckProgress,omitempty"`
+	UploadRatio      float64 `json:"uploadRatio,omitempty"`
+
+	RateDownload        int      `json:"rateDownload,omitempty"`
+	RateUpload          int      `json:"rateUpload,omitempty"`
+	DownloadLimit       int      `json:"downloadLimit,omitempty"`
+	DownloadLimited     bool     `json:"downloadLimited,omitempty"`
+	UploadLimit         int      `json:"uploadLimit,omitempty"`
+	UploadLimited       bool     `json:"uploadLimited,omitempty"`
+	HonorsSessionLimits bool     `json:"honorsSessionLimits,omitempty"`
+	BandwidthPriority   Priority `json:"bandwidthPriority,omitempty"`
+	QueuePosition       int      `json:"queuePosition,omitempty"`
+	PeerLimit           int      `json:"peer-limit,omitempty"`
+	MaxConnectedPeers   int      `json:"maxConnectedPeers,omitempty"`
+	WebseedsSendingToUs int      `json:"webseedsSendingToUs,omitempty"`
+
+	// seed idle limit is in minutes, and the modes are one of SeedModeSession,
+	// SeedModeTorrent or SeedModeUnlimited
+	SeedRatioLimit float64 `json:"seedRatioLimit,omitempty"`
+	SeedRatioMode  int     `json:"seedRatioMode,omitempty"`
+	SeedIdleLimit  int     `json:"seedIdleLimit,omitempty"`
+	SeedIdleMode   int     `json:"seedIdleMode,omitempty"`
+
+	// merged from the files, fileStats, priorities and wanted fields
+	Files []File `json:"files,omitempty"`
+
+	Peers              []Peer      `json:"peers,omitempty"`
+	PeersFrom          PeerSources `json:"peersFrom,omitempty"`
+	PeersConnected     int         `json:"peersConnected,omitempty"`
+	PeersGettingFromUs int         `json:"peersGettingFromUs,omitempty"`
+	PeersSendingToUs   int         `json:"peersSendingToUs,omitempty"`
+
+	Trackers     []Tracker     `json:"trackers,omitempty"`
+	TrackerStats []TrackerStat `json:"trackerStats,omitempty"`
+
+	TrackerList        string `json:"trackerList,omitempty"`
+	SequentialDownload bool   `json:"sequentialDownload,omitempty"`
+
+	ActivityDate       time.Time `json:"activityDate,omitempty"`
+	AddedDate          time.Time `json:"addedDate,omitempty"`
+	DateCreated        time.Time `json:"dateCreated,omitempty"`
+	DoneDate           time.Time `json:"doneDate,omitempty"`
+	EditDate           time.Time `json:"editDate,omitempty"`
+	StartDate          time.Time `json:"startDate,omitempty"`
+	ManualAnnounceTime time.Time `json:"manualAnnounceTime,omitempty"`
+
+	// negative eta values are ETANotAvailable or ETAUnknown
+	ETA                time.Duration `json:"eta,omitempty"`
+	ETAIdle            time.Duration `json:"etaIdle,omitempty"`
+	SecondsDownloading time.Duration `json:"secondsDownloading,omitempty"`
+	SecondsSeeding     time.Duration `json:"secondsSeeding,omitempty"`
+}
+
+// the members of torrent-get that need converting, or merging from several
+// wire fields, before they fit the Torrent model
+type torrentWire struct {
+	ActivityDate       *int64 `json:"activityDate"`
+	AddedDate          *int64 `json:"addedDate"`
+	DateCreated        *int64 `json:"dateCreated"`
+	DoneDate           *int64 `json:"doneDate"`
+	EditDate           *int64 `json:"editDate"`
+	StartDate          *int64 `json:"startDate"`
+	ManualAnnounceTime *int64 `json:"manualAnnounceTime"`
+	ETA                *int64 `json:"eta"`
+	ETAIdle            *int64 `json:"etaIdle"`
+	SecondsDownloading *int64 `json:"secondsDownloading"`
+	SecondsSeeding     *int64 `json:"secondsSeeding"`
+
+	Files      []fileWire     `json:"files"`
+	FileStats  []fileStatWire `json:"fileStats"`
+	Priorities []Priority     `json:"priorities"`
+	Wanted     []flag         `json:"wanted"`
+}
+
+// decodes the unix timestamps and second counts the daemon sends into times
+// and durations, merges the per-file arrays and sizes the piece map, leaving
+// fields absent from the payload untouched
+func (self *Torrent) UnmarshalJSON(d []byte) error {
+
+	// the wire fields sit shallower than the model, so take precedence
+	type plain Torrent
+	type model struct{ *plain }
+	aux := struct {
+		torrentWire
+		model
+	}{model: model{(*plain)(self)}}
+	if err := json.Unmarshal(d, &aux); err != nil {
+		return err
+	}
+	self.convert(&aux.torrentWire)
+	return nil
+}
+
+// applies the wire members to the model, as decoded from either an object
+// or a table row
+func (self *Torrent) convert(aux *torrentWire) {
+	setTime(&self.ActivityDate, aux.ActivityDate)
+	setTime(&self.AddedDate, aux.AddedDate)
+	setTime(&self.DateCreated, aux.DateCreated)
+	setTime(&self.DoneDate, aux.DoneDate)
+	setTime(&self.EditDate, aux.EditDate)
+	setTime(&self.StartDate, aux.StartDate)
+	setTime(&self.ManualAnnounceTime, aux.ManualAnnounceTime)
+	setSeconds(&self.ETA, aux.ETA)
+	setSeconds(&self.ETAIdle, aux.ETAIdle)
+	setSeconds(&self.SecondsDownloading, aux.SecondsDownloading)
+	setSeconds(&self.SecondsSeeding, aux.SecondsSeeding)
+	self.mergeFiles(aux)
+	if self.PieceCount > 0 {
+		self.Pieces.count = self.PieceCount
+	}
+	if self.PieceSize > 0 {
+		self.Pieces.size = self.PieceSize
+	}
+}
+
+// encodes dates as unix seconds and durations as whole seconds, omitting
+// those unset, and the per-file arrays UnmarshalJSON merges, so a Torrent
+// survives a round trip
+func (self Torrent) MarshalJSON() ([]byte, error) {
+	type plain Torrent
+	aux := struct {
+		plain
+		ActivityDate       *int64     `json:"activityDate,omitempty"`
+		AddedDate          *int64     `json:"addedDate,omitempty"`
+		DateCreated        *int64     `json:"dateCreated,omitempty"`
+		DoneDate           *int64     `json:"doneDate,omitempty"`
+		EditDate           *int64     `json:"editDate,omitempty"`
+		StartDate          *int64     `json:"startDate,omitempty"`
+		ManualAnnounceTime *int64     `json:"manualAnnounceTime,omitempty"`
+		ETA                *int64     `json:"eta,omitempty"`
+		ETAIdle            *int64     `json:"etaIdle,omitempty"`
+		SecondsDownloading *int64     `json:"secondsDownloading,omitempty"`
+		SecondsSeeding     *int64     `json:"secondsSeeding,omitempty"`
+		Priorities         []Priority `json:"priorities,omitempty"`
+		Wanted             []bool     `json:"wanted,omitempty"`
+	}{
+		plain:              plain(self),
+		ActivityDate:       unixTime(self.ActivityDate),
+		AddedDate:          unixTime(self.AddedDate),
+		DateCreated:        unixTime(self.DateCreated),
+		DoneDate:           unixTime(self.DoneDate),
+		EditDate:           unixTime(self.EditDate),
+		StartDate:          unixTime(self.StartDate),
+		ManualAnnounceTime: unixTime(self.ManualAnnounceTime),
+		ETA:                wholeSeconds(self.ETA),
+		ETAIdle:            wholeSeconds(self.ETAIdle),
+		SecondsDownloading: wholeSeconds(self.SecondsDownloading),
+		SecondsSeeding:     wholeSeconds(self.SecondsSeeding),
+	}
+	for _, f := range self.Files {
+		aux.Priorities = append(aux.Priorities, f.Priority)
+		aux.Wanted = append(aux.Wanted, f.Wanted)
+	}
+	return json.Marshal(aux)
+}
+
+func unixTime(t time.Time) *int64 {
+	if t.IsZero() {
+		return nil
+	}
+	seconds := t.Unix()
+	return &seconds
+}
+
+func wholeSeconds(d time.Duration) *int64 {
+	if d == 0 {
+		return nil
+	}
+	seconds := int64(d / time.Second)
+	return &seconds
+}
+
+// converts unix seconds to a time when present, where zero means never
+func setTime(t *time.Time, seconds *int64) {
+	if seconds == nil {
+		return
+	} else if *seconds <= 0 {
+		*t = time.Time{}
+	} else {
+		*t = time.Unix(*seconds, 0)
+	}
+}
+
+func setSeconds(d *time.Duration, seconds *int64) {
+	if seconds != nil {
+		*d = time.Duration(*seconds) * time.Second
+	}
+}
+
+// keeps the torrents for which keep returns true
+func Filter(torrents []Torrent, keep func(Torrent) bool) []Torrent {
+	var results []Torrent
+	for _, t := range torrents {
+		if keep(t) {
+			results = append(results, t)
+		}
+	}
+	return results
+}
@@ -0,0 +1,100 @@
+package transmission
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTorrentUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	d := []byte(`{"id":7,"hashString":"9f9165d9a281a9b8e782cd5176bbcc8256fd1871","name":"debian.iso","status":6,"isFinished":false,
+		"percentDone":1,"uploadRatio":1.5,"sizeWhenDone":661651456,"downloadDir":"/srv/torrents","error":0,"errorString":"",
+		"addedDate":1700000000,"doneDate":0,"eta":-1,"secondsSeeding":3600,"rateDownload":0,"rateUpload":2048,
+		"labels":["linux","iso"],"peer-limit":50,"primary-mime-type":"application/octet-stream"}`)
+
+	var tr Torrent
+	if err := json.Unmarshal(d, &tr); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Id != 7 || tr.Name != "debian.iso" || tr.Status != 6 || tr.PercentDone != 1 || tr.UploadRatio != 1.5 || tr.SizeWhenDone != 661651456 || tr.DownloadDir != "/srv/torrents" {
+		t.Logf("unexpected torrent: %+v", tr)
+		t.FailNow()
+	}
+	if !tr.AddedDate.Equal(time.Unix(1700000000, 0)) || !tr.DoneDate.IsZero() || tr.ETA != ETANotAvailable || tr.SecondsSeeding != time.Hour {
+		t.Logf("unexpected times: %+v", tr)
+		t.FailNow()
+	}
+	if tr.RateUpload != 2048 || len(tr.Labels) != 2 || tr.PeerLimit != 50 || tr.MimeType != "application/octet-stream" {
+		t.Logf("unexpected torrent: %+v", tr)
+		t.FailNow()
+	}
+
+	// verify absent fields are left untouched
+	if err := json.Unmarshal([]byte(`{"rateUpload":0}`), &tr); err != nil || tr.AddedDate.IsZero() || tr.ETA != ETANotAvailable || tr.Name != "debian.iso" {
+		t.Logf("unexpected torrent: %+v", tr)
+		t.FailNow()
+	}
+
+	// verify malformed payloads are reported
+	if err := json.Unmarshal([]byte(`{"addedDate":"yesterday"}`), &tr); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestTorrentMarshal(t *testing.T) {
+	t.Parallel()
+
+	original := Torrent{
+		Id:             7,
+		Name:           "debian.iso",
+		Status:         StatusSeeding,
+		PercentDone:    1,
+		PieceCount:     10,
+		Pieces:         NewPieceMap([]byte{0xF0, 0x40}, 10, 0),
+		AddedDate:      time.Unix(1700000000, 0),
+		ETA:            ETANotAvailable,
+		SecondsSeeding: time.Hour,
+		Files:          []File{{Name: "a", Length: 2, BytesCompleted: 1, Priority: PriorityHigh, Wanted: true}, {Name: "b", Length: 3, Priority: PriorityLow}},
+	}
+	d, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// verify the wire format matches what the daemon sends
+	var wire map[string]interface{}
+	json.Unmarshal(d, &wire)
+	if wire["addedDate"] != float64(1700000000) || wire["eta"] != float64(-1) || wire["secondsSeeding"] != float64(3600) {
+		t.Logf("unexpected encoding: %s", d)
+		t.FailNow()
+	}
+	if _, ok := wire["doneDate"]; ok {
+		t.Logf("unset date encoded: %s", d)
+		t.FailNow()
+	}
+
+	// verify the round trip restores the torrent
+	var decoded Torrent
+	if err := json.Unmarshal(d, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Logf("unexpected torrent: %+v", decoded)
+		t.FailNow()
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	l := []Torrent{{Id: 1, Status: StatusSeeding}, {Id: 2, Status: StatusStopped}, {Id: 3, Status: StatusDownloadWait}}
+	if f := Filter(l, func(t Torrent) bool { return t.Status.IsActive() }); len(f) != 1 || f[0].Id != 1 {
+		t.FailNow()
+	}
+	if f := Filter(l, func(t Torrent) bool { return t.Status.IsQueued() }); len(f) != 1 || f[0].Id != 3 {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,124 @@
+package transmission
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// addresses torrents in a request as a numeric id, an info hash, or the
+// RecentlyActive selector; numeric ids are reassigned whenever the daemon
+// restarts, so hashes are the better choice for stored references
+type TorrentID interface {
+	fmt.Stringer
+	wire() interface{}
+}
+
+// the numeric id the daemon assigned this session
+type ID int
+
+func (self ID) String() string {
+	return strconv.Itoa(int(self))
+}
+
+func (self ID) wire() interface{} {
+	return int(self)
+}
+
+// the 40 character hex sha1 info hash, which is stable across restarts
+type Hash string
+
+func (self Hash) String() string {
+	return string(self)
+}
+
+func (self Hash) wire() interface{} {
+	return strings.ToLower(string(self))
+}
+
+func (self Hash) valid() bool {
+	_, err := hex.DecodeString(string(self))
+	return len(self) == 40 && err == nil
+}
+
+// selects torrents by a name the daemon understands rather than by identity
+type Selector string
+
+// the torrents active within the last while, which cannot be combined with
+// any other id in the same request
+const RecentlyActive Selector = "recently-active"
+
+func (self Selector) String() string {
+	return string(self)
+}
+
+func (self Selector) wire() interface{} {
+	return string(self)
+}
+
+// reads an id as written by String, for ids kept in files or flags
+func ParseTorrentID(s string) (TorrentID, error) {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return ID(n), nil
+	} else if s == string(RecentlyActive) {
+		return RecentlyActive, nil
+	} else if h := Hash(s); h.valid() {
+		return h, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidID, s)
+}
+
+// the info hash when it was requested, since it survives restarts, or else
+// the numeric id
+func (self Torrent) TorrentID() TorrentID {
+	if self.HashString != "" {
+		return Hash(self.HashString)
+	}
+	return ID(self.Id)
+}
+
+// the ids of the supplied torrents, for passing results back into a request
+func Select(torrents ...Torrent) []TorrentID {
+	ids := make([]TorrentID, 0, len(torrents))
+	for _, t := range torrents {
+		ids = append(ids, t.TorrentID())
+	}
+	return ids
+}
+
+// rejects selectors where the request concerns exactly one torrent
+func single(id TorrentID, action string) error {
+	if _, ok := id.(Selector); ok {
+		return fmt.Errorf("%w: %s needs a single torrent", ErrInvalidID, action)
+	}
+	return nil
+}
+
+// converts ids to the ids argument, which is nil for every torrent, a bare
+// string for a selector, or else a list mixing numbers and hashes
+func wireIds(ids []TorrentID) (interface{}, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	list := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		switch t := id.(type) {
+		case nil:
+			return nil, fmt.Errorf("%w: nil", ErrInvalidID)
+		case Selector:
+			if t != RecentlyActive {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidID, string(t))
+			} else if len(ids) > 1 {
+				return nil, fmt.Errorf("%w: %s cannot be combined with other ids", ErrInvalidID, t)
+			}
+			return t.wire(), nil
+		case Hash:
+			if !t.valid() {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidID, string(t))
+			}
+		}
+		list = append(list, id.wire())
+	}
+	return list, nil
+}
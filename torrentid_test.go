@@ -0,0 +1,124 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const hash = "3B245504CF5F11BBDBE1201CEA6A6BF45AEE1BC0"
+
+func TestParseTorrentID(t *testing.T) {
+	t.Parallel()
+
+	for s, expected := range map[string]TorrentID{"12": ID(12), hash: Hash(hash), "recently-active": RecentlyActive} {
+		if id, err := ParseTorrentID(s); err != nil || id != expected || id.String() != s {
+			t.Logf("error (%v) or unexpected id for %s: %v", err, s, id)
+			t.FailNow()
+		}
+	}
+	for _, s := range []string{"", "0", "-1", "abc", hash[1:], strings.Replace(hash, "3", "z", 1)} {
+		if _, err := ParseTorrentID(s); !errors.Is(err, ErrInvalidID) {
+			t.Logf("accepted invalid id: %q", s)
+			t.FailNow()
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	ids := Select(Torrent{Id: 1}, Torrent{Id: 2, HashString: hash})
+	if !reflect.DeepEqual(ids, []TorrentID{ID(1), Hash(hash)}) {
+		t.Logf("unexpected ids: %v", ids)
+		t.FailNow()
+	}
+}
+
+func TestWireIds(t *testing.T) {
+	t.Parallel()
+
+	// verify mixed ids are sent as a list with lowercase hashes
+	wire, err := wireIds([]TorrentID{ID(1), Hash(hash)})
+	if err != nil || !reflect.DeepEqual(wire, []interface{}{1, strings.ToLower(hash)}) {
+		t.Logf("error (%v) or unexpected ids: %v", err, wire)
+		t.FailNow()
+	}
+
+	// verify the selector is sent bare and nothing means every torrent
+	if wire, err := wireIds([]TorrentID{RecentlyActive}); err != nil || wire != "recently-active" {
+		t.FailNow()
+	}
+	if wire, err := wireIds(nil); err != nil || wire != nil {
+		t.FailNow()
+	}
+
+	// verify invalid ids and combinations are rejected
+	for _, ids := range [][]TorrentID{{nil}, {Hash("abc")}, {Selector("everything")}, {RecentlyActive, ID(1)}} {
+		if _, err := wireIds(ids); !errors.Is(err, ErrInvalidID) {
+			t.Logf("accepted invalid ids: %v", ids)
+			t.FailNow()
+		}
+	}
+}
+
+func TestTorrentIDs(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server recording the ids of each request
+	var received []interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		received = append(received, c.Arguments.Ids)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify each mutating method sends the ids it was given
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if err := tr.Move(movepath, Hash(hash)); err != nil {
+		t.FailNow()
+	}
+	if err := tr.Remove(ID(3), Hash(hash)); err != nil {
+		t.FailNow()
+	}
+	if err := tr.Resume(RecentlyActive); err != nil {
+		t.FailNow()
+	}
+	expected := []interface{}{
+		[]interface{}{strings.ToLower(hash)},
+		[]interface{}{float64(3), strings.ToLower(hash)},
+		"recently-active",
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Logf("unexpected ids: %v", received)
+		t.FailNow()
+	}
+
+	// verify invalid ids are rejected without a request
+	if err := tr.Remove(Hash("abc")); !errors.Is(err, ErrInvalidID) {
+		t.FailNow()
+	}
+	if err := tr.SetWanted(RecentlyActive, false, 0); !errors.Is(err, ErrInvalidID) {
+		t.FailNow()
+	}
+	if len(received) != 3 {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,143 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// where a tracker is in its announce or scrape cycle
+type TrackerState int
+
+const (
+	TrackerInactive TrackerState = iota
+	TrackerWaiting
+	TrackerQueued
+	TrackerActive
+)
+
+var trackerStateNames = []string{"inactive", "waiting", "queued", "active"}
+
+func (self TrackerState) String() string {
+	if self < 0 || int(self) >= len(trackerStateNames) {
+		return "tracker-state(" + strconv.Itoa(int(self)) + ")"
+	}
+	return trackerStateNames[self]
+}
+
+// a tracker as listed by the trackers field
+type Tracker struct {
+	Id       int    `json:"id"`
+	Announce string `json:"announce"`
+	Scrape   string `json:"scrape"`
+	Sitename string `json:"sitename"`
+	Tier     int    `json:"tier"`
+}
+
+// the state of a tracker as listed by the trackerStats field, where counts
+// are -1 when unknown and times are zero when they have not happened
+type TrackerStat struct {
+	Id       int    `json:"id"`
+	Host     string `json:"host"`
+	Announce string `json:"announce"`
+	Scrape   string `json:"scrape"`
+	Sitename string `json:"sitename"`
+	Tier     int    `json:"tier"`
+	IsBackup bool   `json:"isBackup"`
+
+	AnnounceState         TrackerState `json:"announceState"`
+	HasAnnounced          bool         `json:"hasAnnounced"`
+	LastAnnounceResult    string       `json:"lastAnnounceResult"`
+	LastAnnounceSucceeded bool         `json:"lastAnnounceSucceeded"`
+	LastAnnounceTimedOut  bool         `json:"lastAnnounceTimedOut"`
+	LastAnnouncePeerCount int          `json:"lastAnnouncePeerCount"`
+
+	ScrapeState         TrackerState `json:"scrapeState"`
+	HasScraped          bool         `json:"hasScraped"`
+	LastScrapeResult    string       `json:"lastScrapeResult"`
+	LastScrapeSucceeded bool         `json:"lastScrapeSucceeded"`
+	LastScrapeTimedOut  bool         `json:"lastScrapeTimedOut"`
+
+	SeederCount   int `json:"seederCount"`
+	LeecherCount  int `json:"leecherCount"`
+	DownloadCount int `json:"downloadCount"`
+
+	LastAnnounceStartTime time.Time `json:"lastAnnounceStartTime"`
+	LastAnnounceTime      time.Time `json:"lastAnnounceTime"`
+	NextAnnounceTime      time.Time `json:"nextAnnounceTime"`
+	LastScrapeStartTime   time.Time `json:"lastScrapeStartTime"`
+	LastScrapeTime        time.Time `json:"lastScrapeTime"`
+	NextScrapeTime        time.Time `json:"nextScrapeTime"`
+}
+
+// decodes the unix timestamps the daemon sends into times
+func (self *TrackerStat) UnmarshalJSON(d []byte) error {
+	type plain TrackerStat
+	type model struct{ *plain }
+	aux := struct {
+		LastAnnounceStartTime *int64 `json:"lastAnnounceStartTime"`
+		LastAnnounceTime      *int64 `json:"lastAnnounceTime"`
+		NextAnnounceTime      *int64 `json:"nextAnnounceTime"`
+		LastScrapeStartTime   *int64 `json:"lastScrapeStartTime"`
+		LastScrapeTime        *int64 `json:"lastScrapeTime"`
+		NextScrapeTime        *int64 `json:"nextScrapeTime"`
+		model
+	}{model: model{(*plain)(self)}}
+	if err := json.Unmarshal(d, &aux); err != nil {
+		return err
+	}
+	setTime(&self.LastAnnounceStartTime, aux.LastAnnounceStartTime)
+	setTime(&self.LastAnnounceTime, aux.LastAnnounceTime)
+	setTime(&self.NextAnnounceTime, aux.NextAnnounceTime)
+	setTime(&self.LastScrapeStartTime, aux.LastScrapeStartTime)
+	setTime(&self.LastScrapeTime, aux.LastScrapeTime)
+	setTime(&self.NextScrapeTime, aux.NextScrapeTime)
+	return nil
+}
+
+// encodes times as unix seconds, omitting those unset, so a TrackerStat
+// survives a round trip
+func (self TrackerStat) MarshalJSON() ([]byte, error) {
+	type plain TrackerStat
+	return json.Marshal(struct {
+		plain
+		LastAnnounceStartTime *int64 `json:"lastAnnounceStartTime,omitempty"`
+		LastAnnounceTime      *int64 `json:"lastAnnounceTime,omitempty"`
+		NextAnnounceTime      *int64 `json:"nextAnnounceTime,omitempty"`
+		LastScrapeStartTime   *int64 `json:"lastScrapeStartTime,omitempty"`
+		LastScrapeTime        *int64 `json:"lastScrapeTime,omitempty"`
+		NextScrapeTime        *int64 `json:"nextScrapeTime,omitempty"`
+	}{
+		plain:                 plain(self),
+		LastAnnounceStartTime: unixTime(self.LastAnnounceStartTime),
+		LastAnnounceTime:      unixTime(self.LastAnnounceTime),
+		NextAnnounceTime:      unixTime(self.NextAnnounceTime),
+		LastScrapeStartTime:   unixTime(self.LastScrapeStartTime),
+		LastScrapeTime:        unixTime(self.LastScrapeTime),
+		NextScrapeTime:        unixTime(self.NextScrapeTime),
+	})
+}
+
+// whether the most recent announce to this tracker went wrong
+func (self TrackerStat) Failing() bool {
+	return self.HasAnnounced && !self.LastAnnounceSucceeded
+}
+
+// lists the tracker statistics of the supplied torrents, or of every torrent
+// when none are supplied, keyed by the hash of each torrent
+func (self *Transmission) TrackerStats(ids ...TorrentID) (map[TorrentID][]TrackerStat, error) {
+	return self.TrackerStatsContext(context.Background(), ids...)
+}
+
+func (self *Transmission) TrackerStatsContext(ctx context.Context, ids ...TorrentID) (map[TorrentID][]TrackerStat, error) {
+	torrents, err := self.torrents(ctx, ids, FieldHashString, FieldTrackerStats)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[TorrentID][]TrackerStat, len(torrents))
+	for _, t := range torrents {
+		stats[t.TorrentID()] = t.TrackerStats
+	}
+	return stats, nil
+}
@@ -0,0 +1,115 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackerState(t *testing.T) {
+	t.Parallel()
+	if TrackerActive.String() != "active" || TrackerState(9).String() != "tracker-state(9)" {
+		t.FailNow()
+	}
+}
+
+func TestTrackerStats(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		// verify request
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		if c.Method != "torrent-get" || !reflect.DeepEqual(c.Arguments.Fields, []string{"id", "hashString", "trackerStats"}) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":2,"hashString":"2222222222222222222222222222222222222222","trackerStats":[
+			{"id":0,"host":"tracker.example.org:443","announce":"https://tracker.example.org/announce","tier":0,"announceState":1,"hasAnnounced":true,"lastAnnounceResult":"Could not connect to tracker","lastAnnounceSucceeded":false,"lastAnnounceTime":1700000000,"nextAnnounceTime":1700001800,"lastScrapeTime":0,"scrapeState":0,"seederCount":-1,"leecherCount":-1},
+			{"id":1,"host":"backup.example.org:80","tier":1,"announceState":0,"hasAnnounced":true,"lastAnnounceResult":"Success","lastAnnounceSucceeded":true,"seederCount":12,"leecherCount":3}]}]}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// run TrackerStats
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	stats, err := tr.TrackerStats(ID(2))
+	hash := Hash(strings.Repeat("2", 40))
+	if err != nil || len(stats[hash]) != 2 {
+		t.Logf("error (%v) or unexpected stats: %+v", err, stats)
+		t.FailNow()
+	}
+	first, second := stats[hash][0], stats[hash][1]
+	if !first.Failing() || first.AnnounceState != TrackerWaiting || first.LastAnnounceResult != "Could not connect to tracker" || first.SeederCount != -1 {
+		t.Logf("unexpected tracker: %+v", first)
+		t.FailNow()
+	}
+	if !first.LastAnnounceTime.Equal(time.Unix(1700000000, 0)) || !first.NextAnnounceTime.Equal(time.Unix(1700001800, 0)) || !first.LastScrapeTime.IsZero() {
+		t.Logf("unexpected times: %+v", first)
+		t.FailNow()
+	}
+	if second.Failing() || second.Tier != 1 || second.SeederCount != 12 || second.LeecherCount != 3 {
+		t.Logf("unexpected tracker: %+v", second)
+		t.FailNow()
+	}
+
+	// verify failures are returned
+	ts.Close()
+	tr.Retry.Attempts = 1
+	if _, err := tr.TrackerStats(); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestTrackersJSONRPC(t *testing.T) {
+	t.Parallel()
+
+	// verify the nested snake_case names reach the model
+	rc := &command{}
+	err := jsonrpc{}.decode(strings.NewReader(`{"jsonrpc":"2.0","result":{"torrents":[{"id":1,"trackers":[{"announce":"a","tier":2}],"tracker_stats":[{"last_announce_succeeded":true,"has_announced":true,"next_announce_time":1700000000,"seeder_count":4}]}]},"id":1}`), rc)
+	if err != nil || len(rc.Arguments.Torrents) != 1 {
+		t.FailNow()
+	}
+	tr := rc.Arguments.Torrents[0]
+	if len(tr.Trackers) != 1 || tr.Trackers[0].Tier != 2 || len(tr.TrackerStats) != 1 || tr.TrackerStats[0].Failing() || tr.TrackerStats[0].SeederCount != 4 || tr.TrackerStats[0].NextAnnounceTime.Unix() != 1700000000 {
+		t.Logf("unexpected torrent: %+v", tr)
+		t.FailNow()
+	}
+}
+
+func TestTrackerStatMarshal(t *testing.T) {
+	t.Parallel()
+
+	// verify tracker stats survive a round trip within a torrent
+	original := Torrent{Id: 1, TrackerStats: []TrackerStat{{Host: "a", SeederCount: -1, HasAnnounced: true, LastAnnounceTime: time.Unix(1700000000, 0)}}}
+	d, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Torrent
+	if err := json.Unmarshal(d, &decoded); err != nil || !reflect.DeepEqual(decoded, original) {
+		t.Logf("error (%v) or unexpected torrent: %s", err, d)
+		t.FailNow()
+	}
+	if strings.Contains(string(d), "nextAnnounceTime") {
+		t.Logf("unset time encoded: %s", d)
+		t.FailNow()
+	}
+}
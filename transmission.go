@@ -2,19 +2,32 @@ package transmission
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
 	"path"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 const transmissionConfigPath = "/etc/transmission-daemon/settings.json"
+const defaultHost = "127.0.0.1"
+const defaultScheme = "http"
+const passwordEnv = "TRANSMISSION_RPC_PASSWORD"
+const defaultTimeout = 30 * time.Second
+const unixPrefix = "unix:"
 
 var readFile = ioutil.ReadFile
+var getenv = os.Getenv
 
 type filesystem interface {
 	ReadFile(string) ([]byte, error)
@@ -22,98 +35,367 @@ type filesystem interface {
 
 type Transmission struct {
 	sync.RWMutex
-	Token     string `json:"-"`
+	Token string `json:"-"`
+	// overrides the address to connect to, where a port included here (eg.
+	// "seedbox:9091" or "[::1]:9091") takes precedence over Port
+	Host      string `json:"-"`
+	Bind      string `json:"rpc-bind-address"`
+	Username  string `json:"rpc-username"`
+	Password  string `json:"-"`
+	Scheme    string `json:"-"`
+	CA        string `json:"-"`
+	Cert      string `json:"-"`
+	Key       string `json:"-"`
+	Insecure  bool   `json:"-"`
 	Downloads string `json:"download-dir"`
 	Port      int    `json:"rpc-port"`
 	Uri       string `json:"rpc-url"`
-}
 
-type torrent struct {
-	Id       int  `json:"id,omitempty"`
-	Finished bool `json:"isFinished,omitempty"`
+	// governs retries, where the zero value uses the defaults
+	Retry RetryPolicy `json:"-"`
+
+	// wire format, where the zero value negotiates with the daemon
+	Protocol Protocol `json:"-"`
+
+	// optional overrides for the default client, which has a timeout and
+	// reuses connections; the tls options above are ignored when supplied
+	Client    *http.Client      `json:"-"`
+	Transport http.RoundTripper `json:"-"`
+
+	client    *http.Client
+	clientKey string
+	version   *Version
 }
 
 type arguments struct {
-	Torrents []torrent `json:"torrents,omitempty"`
-	Ids      []int     `json:"ids,omitempty"`
-	Fields   []string  `json:"fields,omitempty"`
-	Location string    `json:"location,omitempty"`
-	Metainfo string    `json:"metainfo,omitempty"`
-	Move     bool      `json:"move,omitempty"`
+	Torrents         torrentList `json:"torrents,omitempty"`
+	Removed          []int       `json:"removed,omitempty"`
+	TorrentAdded     *Torrent    `json:"torrent-added,omitempty"`
+	TorrentDuplicate *Torrent    `json:"torrent-duplicate,omitempty"`
+	Ids              interface{} `json:"ids,omitempty"`
+	Fields           []string    `json:"fields,omitempty"`
+	Format           string      `json:"format,omitempty"`
+	Location         string      `json:"location,omitempty"`
+	Metainfo         string      `json:"metainfo,omitempty"`
+	Move             bool        `json:"move,omitempty"`
+	*TorrentSettings
+
+	RpcVersion        int    `json:"rpc-version,omitempty"`
+	RpcVersionMinimum int    `json:"rpc-version-minimum,omitempty"`
+	RpcVersionSemver  string `json:"rpc-version-semver,omitempty"`
+	Version           string `json:"version,omitempty"`
 }
 
 type command struct {
 	Method    string    `json:"method"`
 	Result    string    `json:"result,omitempty"`
 	Arguments arguments `json:"arguments,omitempty"`
+
+	// streams torrents as they are decoded, and holds the error it stopped with
+	each func(Torrent) error
+	stop error
 }
 
-var errorRetryFailed = errors.New("failed to get a valid response from transmission")
+var errorEmptyCredentials = errors.New("no credentials found in file")
+var errorBadCA = errors.New("no certificates found in ca bundle")
+
+// sends the command using the configured or negotiated protocol
+func (self *Transmission) send(ctx context.Context, cmd *command) (arguments, error) {
+	if err := self.supports(ctx, cmd.Method); err != nil {
+		return arguments{}, err
+	}
+	proto, err := self.protocol(ctx)
+	if err != nil {
+		return arguments{}, err
+	}
+	return self.exchange(ctx, proto, cmd)
+}
+
+// returns the configured protocol, otherwise chooses one by the rpc-version
+// the daemon advertises
+func (self *Transmission) protocol(ctx context.Context) (protocol, error) {
+	self.RLock()
+	selected := self.Protocol
+	self.RUnlock()
+	if selected == ProtocolLegacy {
+		return legacy{}, nil
+	} else if selected == ProtocolJSONRPC {
+		return jsonrpc{}, nil
+	}
+
+	v, err := self.VersionContext(ctx)
+	if err != nil {
+		return nil, err
+	} else if v.RPC >= jsonrpcVersion {
+		return jsonrpc{}, nil
+	}
+	return legacy{}, nil
+}
 
 // consolidated method for sending http requests to transmission
-// computes the endpoint, loops with 3 retries and grabbing tokens
+// computes the endpoint, loops according to the retry policy and grabbing tokens
+// cancelling ctx aborts both the request in flight and any pending retry
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L61
-func (self *Transmission) send(cmd *command) ([]torrent, error) {
+func (self *Transmission) exchange(ctx context.Context, proto protocol, cmd *command) (arguments, error) {
 
 	// compute RPC address
-	self.RLock()
-	route := "http://127.0.0.1:" + path.Join(strconv.Itoa(self.Port), self.Uri, "rpc/")
-	self.RUnlock()
+	route := self.route()
 
 	// error for later
-	var results []torrent
+	var results arguments
 
-	// json marshal cmd for request
-	d, _ := json.Marshal(cmd)
+	// encode cmd for request
+	d, err := proto.encode(cmd)
+	if err != nil {
+		return results, &Error{Method: cmd.Method, Err: err}
+	}
 
 	// prepare client to send requests
-	c := http.Client{}
+	c, err := self.httpClient()
+	if err != nil {
+		return results, &Error{Method: cmd.Method, Err: err}
+	}
 
-	// three-attempts per operation
-	for i := 0; i < 3; i++ {
+	// the default timeout covers reading the whole body, which would cut long
+	// streams short, so those are bounded by ctx alone
+	self.RLock()
+	supplied := self.Client != nil
+	self.RUnlock()
+	if cmd.each != nil && !supplied {
+		stream := *c
+		stream.Timeout = 0
+		c = &stream
+	}
+
+	// apply the retry policy defaults
+	self.RLock()
+	policy := self.Retry.normalize()
+	self.RUnlock()
+	var refreshed bool
+	var last *Error
+
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		last = &Error{Method: cmd.Method, Attempts: attempt}
 
 		// prepare request
-		r, err := http.NewRequest("POST", route, bytes.NewReader(d))
+		r, err := http.NewRequestWithContext(ctx, "POST", route, bytes.NewReader(d))
 		if err != nil {
-			return results, err
+			last.Err = err
+			return results, last
 		}
 
-		// apply token header
+		// apply token header and credentials
 		self.RLock()
 		r.Header.Set("X-Transmission-Session-Id", self.Token)
+		if self.Username != "" || self.Password != "" {
+			r.SetBasicAuth(self.Username, self.Password)
+		}
 		self.RUnlock()
 
 		// deal with the aftermath
 		resp, err := c.Do(r)
 		if err != nil || resp == nil {
-			time.Sleep(time.Second * 2)
-			continue
-		} else if resp.StatusCode == http.StatusConflict {
-			self.Lock()
-			self.Token = resp.Header.Get("X-Transmission-Session-Id")
-			self.Unlock()
-			continue
-		} else if resp.StatusCode == http.StatusOK {
-			rc := &command{}
-			decoder := json.NewDecoder(resp.Body)
-			decoder.Decode(rc)
-			if rc.Result != "success" {
-				time.Sleep(time.Second * 2)
+			last.Err, last.transport = err, true
+			if ctx.Err() != nil {
+				last.Err = ctx.Err()
+				return results, last
+			}
+		} else {
+			rc := &command{each: cmd.each}
+			var malformed error
+			if resp.StatusCode == http.StatusOK {
+				malformed = proto.decode(resp.Body, rc)
+			}
+			resp.Body.Close()
+			last.Status, last.Result = resp.StatusCode, rc.Result
+			if rc.stop != nil {
+				return results, rc.stop
+			} else if malformed != nil {
+				last.Err = malformed
+				if ctx.Err() != nil {
+					last.Err = ctx.Err()
+					return results, last
+				} else if isMalformed(malformed) && !errors.Is(malformed, ErrMalformed) {
+					last.Err = fmt.Errorf("%w: %w", ErrMalformed, malformed)
+				}
+				if cmd.each != nil {
+					return results, last
+				}
+			} else if resp.StatusCode == http.StatusUnauthorized {
+				return results, last
+			} else if resp.StatusCode == http.StatusConflict {
+				self.Lock()
+				self.Token = resp.Header.Get("X-Transmission-Session-Id")
+				self.Unlock()
+				if !policy.CountConflicts && !refreshed {
+					refreshed = true
+					attempt--
+				}
 				continue
+			} else if resp.StatusCode == http.StatusOK && rc.Result == "success" {
+				results = rc.Arguments
+				return results, nil
+			} else if resp.StatusCode == http.StatusOK && cmd.each != nil {
+				// torrents may already have been passed to the callback
+				return results, last
 			}
-			results = rc.Arguments.Torrents
-			return results, nil
 		}
+
+		// wait before the next attempt, unless the failure is final
+		if attempt == policy.Attempts || !policy.retryable(last) {
+			break
+		}
+		if err := sleep(ctx, policy.delay(attempt)); err != nil {
+			last.Err = err
+			return results, last
+		}
+	}
+	return results, last
+}
+
+// waits for the delay to pass unless the context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// computes the rpc endpoint, preferring an explicit Host, then a specific
+// rpc-bind-address, and falling back to the loopback address
+// @note: ipv6 literals are accepted with or without surrounding brackets
+func (self *Transmission) route() string {
+	self.RLock()
+	defer self.RUnlock()
+	scheme := self.Scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	if socket(self.Host, self.Bind) != "" {
+		return scheme + "://localhost" + path.Join("/", self.Uri, "rpc/")
+	}
+	host, port := strings.Trim(self.Host, "[]"), strconv.Itoa(self.Port)
+	if h, p, err := net.SplitHostPort(self.Host); err == nil {
+		host, port = h, p
+	}
+	if host == "" && !wildcard(self.Bind) {
+		host = strings.Trim(self.Bind, "[]")
+	}
+	if host == "" {
+		host = defaultHost
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + path.Join("/", self.Uri, "rpc/")
+}
+
+// returns the unix socket path when bound to one, unless a Host overrides it
+func socket(host, bind string) string {
+	if host != "" || !strings.HasPrefix(bind, unixPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(bind, unixPrefix)
+}
+
+// returns the supplied client, wraps the supplied transport, or otherwise
+// builds a default client which is reused until its settings change
+// @note: connections are pooled by the transport, not the client
+func (self *Transmission) httpClient() (*http.Client, error) {
+	self.RLock()
+	c, transport, sock := self.Client, self.Transport, socket(self.Host, self.Bind)
+	key := fmt.Sprint(sock, self.Scheme, self.CA, self.Cert, self.Key, self.Insecure)
+	if c == nil && transport == nil && self.client != nil && self.clientKey == key {
+		c = self.client
+	}
+	self.RUnlock()
+	if c != nil {
+		return c, nil
+	} else if transport != nil {
+		return &http.Client{Timeout: defaultTimeout, Transport: transport}, nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if sock != "" {
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", sock)
+		}
+	}
+	if self.secure() {
+		config, err := self.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = config
+	}
+	c = &http.Client{Timeout: defaultTimeout, Transport: t}
+
+	self.Lock()
+	self.client, self.clientKey = c, key
+	self.Unlock()
+	return c, nil
+}
+
+func (self *Transmission) secure() bool {
+	self.RLock()
+	defer self.RUnlock()
+	return self.Scheme == "https"
+}
+
+// builds the tls configuration from the optional ca bundle and client
+// certificate, where the key may be bundled in the certificate file
+// @note: Insecure disables verification entirely, so is only for lab use
+func (self *Transmission) tlsConfig() (*tls.Config, error) {
+	self.RLock()
+	defer self.RUnlock()
+	config := &tls.Config{InsecureSkipVerify: self.Insecure}
+
+	if self.CA != "" {
+		d, e := readFile(self.CA)
+		if e != nil {
+			return nil, e
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(d) {
+			return nil, errorBadCA
+		}
+		config.RootCAs = pool
 	}
-	return results, errorRetryFailed
+
+	if self.Cert != "" {
+		key := self.Key
+		if key == "" {
+			key = self.Cert
+		}
+		c, e := readFile(self.Cert)
+		if e != nil {
+			return nil, e
+		}
+		k, e := readFile(key)
+		if e != nil {
+			return nil, e
+		}
+		pair, e := tls.X509KeyPair(c, k)
+		if e != nil {
+			return nil, e
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	return config, nil
 }
 
-func (self *Transmission) ids(torrents ...torrent) []int {
-	var ids []int
-	for _, t := range torrents {
-		ids = append(ids, t.Id)
+// identifies bind addresses that listen on every interface, and therefore
+// cannot be dialed directly
+func wildcard(address string) bool {
+	switch strings.Trim(address, "[]") {
+	case "", "0.0.0.0", "::":
+		return true
 	}
-	return ids
+	return false
 }
 
 func (self *Transmission) Configure(path string) error {
@@ -128,58 +410,184 @@ func (self *Transmission) Configure(path string) error {
 	}
 
 	// unmarshal onto self
-	return json.Unmarshal(d, self)
+	if e = json.Unmarshal(d, self); e != nil {
+		return e
+	}
+
+	// settings.json only holds a salted hash, so look elsewhere for the password
+	self.Lock()
+	if self.Password == "" {
+		self.Password = getenv(passwordEnv)
+	}
+	self.Unlock()
+	return nil
+}
+
+// loads credentials from a file containing either "username:password" or
+// only the password, in which case the configured username is kept
+func (self *Transmission) Credentials(path string) error {
+	d, e := readFile(path)
+	if e != nil {
+		return e
+	}
+	line := strings.TrimSpace(strings.SplitN(string(d), "\n", 2)[0])
+	if line == "" {
+		return errorEmptyCredentials
+	}
+
+	self.Lock()
+	defer self.Unlock()
+	if i := strings.Index(line, ":"); i >= 0 {
+		self.Username, self.Password = line[:i], line[i+1:]
+	} else {
+		self.Password = line
+	}
+	return nil
 }
 
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L129
-func (self *Transmission) Get() ([]torrent, error) {
-	cmd := &command{Method: "torrent-get", Arguments: arguments{Fields: []string{"id", "isFinished"}}}
-	return self.send(cmd)
+func (self *Transmission) Get() ([]Torrent, error) {
+	return self.GetContext(context.Background())
 }
 
-func (self *Transmission) Finished() ([]torrent, error) {
-	torrents, err := self.Get()
-	var results []torrent
-	for _, t := range torrents {
-		if t.Finished == true {
-			results = append(results, t)
-		}
-	}
-	return results, err
+func (self *Transmission) GetContext(ctx context.Context) ([]Torrent, error) {
+	return self.GetFieldsContext(ctx, FieldFinished)
+}
+
+func (self *Transmission) Finished() ([]Torrent, error) {
+	return self.FinishedContext(context.Background())
+}
+
+func (self *Transmission) FinishedContext(ctx context.Context) ([]Torrent, error) {
+	torrents, err := self.GetContext(ctx)
+	return Filter(torrents, func(t Torrent) bool { return t.Finished }), err
 }
 
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L408
 // @note: returns success status even if files are not moved due to permissions
 //   be careful if misconfigured data may not be relocated, only unlinked of -r
-func (self *Transmission) Move(path string, torrents []torrent) error {
-	if len(torrents) == 0 {
+func (self *Transmission) Move(path string, ids ...TorrentID) error {
+	return self.MoveContext(context.Background(), path, ids...)
+}
+
+func (self *Transmission) MoveContext(ctx context.Context, path string, ids ...TorrentID) error {
+	if len(ids) == 0 {
 		return nil
 	}
-	cmd := &command{Method: "torrent-set-location", Arguments: arguments{Ids: self.ids(torrents...), Location: path, Move: true}}
-	_, err := self.send(cmd)
+	wire, err := wireIds(ids)
+	if err != nil {
+		return err
+	}
+	cmd := &command{Method: "torrent-set-location", Arguments: arguments{Ids: wire, Location: path, Move: true}}
+	_, err = self.send(ctx, cmd)
 	return err
 }
 
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L394
-func (self *Transmission) Remove(torrents []torrent) error {
-	if len(torrents) == 0 {
+func (self *Transmission) Remove(ids ...TorrentID) error {
+	return self.RemoveContext(context.Background(), ids...)
+}
+
+func (self *Transmission) RemoveContext(ctx context.Context, ids ...TorrentID) error {
+	if len(ids) == 0 {
 		return nil
 	}
-	cmd := &command{Method: "torrent-remove", Arguments: arguments{Ids: self.ids(torrents...)}}
-	_, err := self.send(cmd)
+	wire, err := wireIds(ids)
+	if err != nil {
+		return err
+	}
+	cmd := &command{Method: "torrent-remove", Arguments: arguments{Ids: wire}}
+	_, err = self.send(ctx, cmd)
 	return err
 }
 
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L358
 func (self *Transmission) Add(meta string) error {
-	cmd := &command{Method: "torrent-add", Arguments: arguments{Metainfo: meta}}
-	_, err := self.send(cmd)
+	return self.AddContext(context.Background(), meta)
+}
+
+func (self *Transmission) AddContext(ctx context.Context, meta string) error {
+	_, err := self.AddTorrentContext(ctx, meta)
+	if errors.Is(err, ErrDuplicate) {
+		return nil
+	}
 	return err
 }
 
+// adds base64 encoded metainfo like Add, but returns the id, name and hash of
+// the new torrent, or of the existing one along with ErrDuplicate
+func (self *Transmission) AddTorrent(meta string) (Torrent, error) {
+	return self.AddTorrentContext(context.Background(), meta)
+}
+
+func (self *Transmission) AddTorrentContext(ctx context.Context, meta string) (Torrent, error) {
+	cmd := &command{Method: "torrent-add", Arguments: arguments{Metainfo: meta}}
+	args, err := self.send(ctx, cmd)
+	if err != nil {
+		return Torrent{}, err
+	} else if args.TorrentDuplicate != nil {
+		return *args.TorrentDuplicate, ErrDuplicate
+	} else if args.TorrentAdded != nil {
+		return *args.TorrentAdded, nil
+	}
+	return Torrent{}, nil
+}
+
+// queues the supplied torrents to start
 // @link: https://trac.transmissionbt.com/browser/trunk/extras/rpc-spec.txt#L76
-func (self *Transmission) Resume() error {
-	cmd := &command{Method: "torrent-start-now"}
-	_, err := self.send(cmd)
+func (self *Transmission) Start(ids ...TorrentID) error {
+	return self.StartContext(context.Background(), ids...)
+}
+
+func (self *Transmission) StartContext(ctx context.Context, ids ...TorrentID) error {
+	return self.action(ctx, "torrent-start", ids)
+}
+
+// starts the supplied torrents immediately, ignoring the download queue
+func (self *Transmission) StartNow(ids ...TorrentID) error {
+	return self.StartNowContext(context.Background(), ids...)
+}
+
+func (self *Transmission) StartNowContext(ctx context.Context, ids ...TorrentID) error {
+	return self.action(ctx, "torrent-start-now", ids)
+}
+
+func (self *Transmission) Stop(ids ...TorrentID) error {
+	return self.StopContext(context.Background(), ids...)
+}
+
+func (self *Transmission) StopContext(ctx context.Context, ids ...TorrentID) error {
+	return self.action(ctx, "torrent-stop", ids)
+}
+
+// sends a torrent action for the ids, doing nothing when there are none
+// since the daemon would otherwise apply it to every torrent
+func (self *Transmission) action(ctx context.Context, method string, ids []TorrentID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	wire, err := wireIds(ids)
+	if err != nil {
+		return err
+	}
+	cmd := &command{Method: method, Arguments: arguments{Ids: wire}}
+	_, err = self.send(ctx, cmd)
+	return err
+}
+
+// starts the supplied torrents immediately, or every torrent, including
+// those deliberately paused, when none are supplied
+// Deprecated: use StartNow, which never acts on every torrent
+func (self *Transmission) Resume(ids ...TorrentID) error {
+	return self.ResumeContext(context.Background(), ids...)
+}
+
+func (self *Transmission) ResumeContext(ctx context.Context, ids ...TorrentID) error {
+	wire, err := wireIds(ids)
+	if err != nil {
+		return err
+	}
+	cmd := &command{Method: "torrent-start-now", Arguments: arguments{Ids: wire}}
+	_, err = self.send(ctx, cmd)
 	return err
 }
@@ -1,26 +1,43 @@
 package transmission
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
 	fakeFSData  []byte
 	fakeFSError error
 	fsError     = errors.New("fake filesystem error")
+	fakeEnv     = map[string]string{}
 
 	getTorrentsSuccess    = []byte(`{"result":"success","arguments": {"torrents": [{"id": 1,"isFinished": false},{"id": 2,"isFinished": false},{"id": 3,"isFinished": false},{"id": 4,"isFinished": false},{"id": 5,"isFinished": false},{"id": 6,"isFinished": true},{"id": 7,"isFinished": true},{"id": 8,"isFinished": true},{"id": 9,"isFinished": true},{"id": 10,"isFinished": true}]}}`)
 	moveTorrentsSuccess   = []byte(`{"result":"success"}`)
 	removeTorrentsSuccess = []byte(`{"result":"success"}`)
-	addTorrentSuccess     = []byte(`{"result":"success"}`)
+	addTorrentSuccess     = []byte(`{"result":"success","arguments":{"torrent-added":{"id":5,"name":"a","hashString":"3b245504cf5f11bbdbe1201cea6a6bf45aee1bc0"}}}`)
 	resumeTorrentsSuccess = []byte(`{"result":"success"}`)
 
+	sessionGetSuccess = []byte(`{"result":"success","arguments":{"rpc-version":17}}`)
+
 	getTorrentsFail    = []byte(`{"result":"not success"}`)
 	moveTorrentsFail   = []byte(`{"result":"not success"}`)
 	removeTorrentsFail = []byte(`{"result":"not success"}`)
@@ -34,6 +51,7 @@ var (
 
 func init() {
 	readFile = func(path string) ([]byte, error) { return fakeFSData, fakeFSError }
+	getenv = func(key string) string { return fakeEnv[key] }
 }
 
 func TestPlacebo(t *testing.T) {
@@ -80,7 +98,7 @@ func TestGetSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Get
 	l, err := tr.Get()
@@ -127,7 +145,7 @@ func TestGetFail(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Get
 	_, err := tr.Get()
@@ -202,7 +220,7 @@ func TestFinishedSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Finished
 	l, err := tr.Finished()
@@ -251,7 +269,7 @@ func TestMoveSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// acquire finished list
 	l, err := tr.Finished()
@@ -261,7 +279,7 @@ func TestMoveSuccess(t *testing.T) {
 	}
 
 	// run Move
-	err = tr.Move(movepath, l)
+	err = tr.Move(movepath, Select(l...)...)
 	if err != nil {
 		t.Logf("unexpected error: %v\n", err)
 		t.FailNow()
@@ -307,7 +325,7 @@ func TestMoveFail(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// acquire finished list
 	l, err := tr.Finished()
@@ -317,7 +335,7 @@ func TestMoveFail(t *testing.T) {
 	}
 
 	// run Move
-	err = tr.Move(movepath, l)
+	err = tr.Move(movepath, Select(l...)...)
 	if err == nil {
 		t.Logf("expected error, but got: %v\n", err)
 		t.FailNow()
@@ -331,7 +349,7 @@ func TestMoveFailNoList(t *testing.T) {
 	tr := Transmission{}
 
 	// run Move /w empty array
-	err := tr.Move(movepath, []torrent{})
+	err := tr.Move(movepath)
 	if err != nil {
 		t.Logf("expected error, but got: %v\n", err)
 		t.FailNow()
@@ -377,7 +395,7 @@ func TestRemoveSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// acquire finished list
 	l, err := tr.Finished()
@@ -387,7 +405,7 @@ func TestRemoveSuccess(t *testing.T) {
 	}
 
 	// run Remove
-	err = tr.Remove(l)
+	err = tr.Remove(Select(l...)...)
 	if err != nil {
 		t.Logf("unexpected error: %v\n", err)
 		t.FailNow()
@@ -433,7 +451,7 @@ func TestRemoveFail(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// acquire finished list
 	l, err := tr.Finished()
@@ -443,7 +461,7 @@ func TestRemoveFail(t *testing.T) {
 	}
 
 	// run Remove
-	err = tr.Remove(l)
+	err = tr.Remove(Select(l...)...)
 	if err == nil {
 		t.Logf("expected error, but got: %v\n", err)
 		t.FailNow()
@@ -457,7 +475,7 @@ func TestRemoveFailNoList(t *testing.T) {
 	tr := Transmission{}
 
 	// run Remove /w empty array
-	err := tr.Remove([]torrent{})
+	err := tr.Remove()
 	if err != nil {
 		t.Logf("expected error, but got: %v\n", err)
 		t.FailNow()
@@ -501,7 +519,7 @@ func TestAddSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Add
 	err := tr.Add(metainfo)
@@ -509,6 +527,13 @@ func TestAddSuccess(t *testing.T) {
 		t.Logf("unexpected error: %v\n", err)
 		t.FailNow()
 	}
+
+	// verify AddTorrent returns the new torrent
+	added, err := tr.AddTorrent(metainfo)
+	if err != nil || added.Id != 5 || added.Name != "a" || added.TorrentID() != Hash("3b245504cf5f11bbdbe1201cea6a6bf45aee1bc0") {
+		t.Logf("error (%v) or unexpected torrent: %+v\n", err, added)
+		t.FailNow()
+	}
 }
 
 func TestAddFail(t *testing.T) {
@@ -548,7 +573,7 @@ func TestAddFail(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Add
 	err := tr.Add(metainfo)
@@ -558,6 +583,80 @@ func TestAddFail(t *testing.T) {
 	}
 }
 
+func TestAddDuplicate(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{"torrent-duplicate":{"id":2,"name":"b"}}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the existing torrent is returned with ErrDuplicate
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	existing, err := tr.AddTorrent(metainfo)
+	if !errors.Is(err, ErrDuplicate) || existing.Id != 2 || existing.Name != "b" {
+		t.Logf("error (%v) or unexpected torrent: %+v\n", err, existing)
+		t.FailNow()
+	}
+
+	// verify Add still treats a duplicate as success
+	if err := tr.Add(metainfo); err != nil {
+		t.Logf("unexpected error: %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server recording each method and its ids
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		received = append(received, fmt.Sprint(c.Method, c.Arguments.Ids))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify each action is sent for its selection only
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if tr.Start(ID(1)) != nil || tr.StartNow(ID(2), ID(3)) != nil || tr.Stop(RecentlyActive) != nil {
+		t.FailNow()
+	}
+
+	// verify an empty selection never reaches the daemon
+	if tr.Start() != nil || tr.StartNow() != nil || tr.Stop() != nil {
+		t.FailNow()
+	}
+	expected := []string{"torrent-start[1]", "torrent-start-now[2 3]", "torrent-stoprecently-active"}
+	if !reflect.DeepEqual(received, expected) {
+		t.Logf("unexpected requests: %q", received)
+		t.FailNow()
+	}
+}
+
 func TestResumeSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -595,7 +694,7 @@ func TestResumeSuccess(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Resume
 	err := tr.Resume()
@@ -642,7 +741,7 @@ func TestResumeFail(t *testing.T) {
 	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
 
 	// configure transmission instance
-	tr := Transmission{Port: port}
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
 
 	// run Resume
 	err := tr.Resume()
@@ -693,3 +792,427 @@ func TestConfigureFailReadFile(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestConfigureBindAddress(t *testing.T) {
+	tr := Transmission{}
+
+	// unset error & set data
+	fakeFSError = nil
+	fakeFSData = []byte(`{"rpc-port": 3000, "rpc-bind-address": "10.0.0.5"}`)
+
+	// run & verify
+	err := tr.Configure("")
+	if err != nil || tr.route() != "http://10.0.0.5:3000/rpc" {
+		t.Logf("unexpected error (%v) or route (%s)", err, tr.route())
+		t.FailNow()
+	}
+}
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+
+	routes := []struct {
+		tr    *Transmission
+		route string
+	}{
+		{&Transmission{Port: 9091}, "http://127.0.0.1:9091/rpc"},
+		{&Transmission{Port: 9091, Uri: "/transmission/"}, "http://127.0.0.1:9091/transmission/rpc"},
+		{&Transmission{Port: 9091, Bind: "0.0.0.0"}, "http://127.0.0.1:9091/rpc"},
+		{&Transmission{Port: 9091, Bind: "::"}, "http://127.0.0.1:9091/rpc"},
+		{&Transmission{Port: 9091, Bind: "192.168.1.20"}, "http://192.168.1.20:9091/rpc"},
+		{&Transmission{Port: 9091, Bind: "fe80::1"}, "http://[fe80::1]:9091/rpc"},
+		{&Transmission{Port: 9091, Bind: "192.168.1.20", Host: "seedbox"}, "http://seedbox:9091/rpc"},
+		{&Transmission{Port: 9091, Host: "[::1]"}, "http://[::1]:9091/rpc"},
+		{&Transmission{Port: 9091, Host: "::1"}, "http://[::1]:9091/rpc"},
+		{&Transmission{Port: 9091, Host: "seedbox:443"}, "http://seedbox:443/rpc"},
+		{&Transmission{Port: 9091, Host: "[::1]:8080"}, "http://[::1]:8080/rpc"},
+		{&Transmission{Port: 9091, Bind: "unix:/run/transmission.sock", Uri: "/transmission/"}, "http://localhost/transmission/rpc"},
+		{&Transmission{Port: 9091, Bind: "unix:/run/transmission.sock", Host: "seedbox"}, "http://seedbox:9091/rpc"},
+	}
+
+	for _, r := range routes {
+		if route := r.tr.route(); route != r.route {
+			t.Logf("expected %s, but got %s", r.route, route)
+			t.Fail()
+		}
+	}
+}
+
+func TestAuthenticationSuccess(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+
+		// check credentials
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// check token
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(getTorrentsSuccess)
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// configure transmission instance
+	tr := Transmission{Port: port, Username: "user", Password: "secret"}
+
+	// run Get
+	l, err := tr.Get()
+	if err != nil || len(l) != 10 {
+		t.Logf("error (%s) or list does not have 10 records (%+v)", err, l)
+		t.FailNow()
+	}
+
+	// run Get with bad credentials
+	tr.Password = "wrong"
+	if _, err := tr.Get(); !errors.Is(err, ErrUnauthorized) {
+		t.Logf("expected unauthorized error, but got: %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestConfigurePasswordEnv(t *testing.T) {
+	tr := Transmission{}
+
+	// unset error & set data
+	fakeFSError = nil
+	fakeFSData = []byte(`{"rpc-port": 3000, "rpc-username": "user", "rpc-password": "{salted-hash"}`)
+	fakeEnv[passwordEnv] = "secret"
+	defer delete(fakeEnv, passwordEnv)
+
+	// run & verify
+	err := tr.Configure("")
+	if err != nil || tr.Username != "user" || tr.Password != "secret" {
+		t.FailNow()
+	}
+
+	// verify an explicit password is kept
+	tr.Password = "explicit"
+	if err := tr.Configure(""); err != nil || tr.Password != "explicit" {
+		t.FailNow()
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	tr := Transmission{Username: "configured"}
+	fakeFSError = nil
+
+	// password only
+	fakeFSData = []byte("secret\n")
+	if err := tr.Credentials(""); err != nil || tr.Username != "configured" || tr.Password != "secret" {
+		t.FailNow()
+	}
+
+	// username and password
+	fakeFSData = []byte("user:pass:word\nignored")
+	if err := tr.Credentials(""); err != nil || tr.Username != "user" || tr.Password != "pass:word" {
+		t.FailNow()
+	}
+
+	// empty file
+	fakeFSData = []byte("\n")
+	if err := tr.Credentials(""); err == nil {
+		t.FailNow()
+	}
+
+	// unreadable file
+	fakeFSError = fsError
+	if err := tr.Credentials(""); err == nil {
+		t.FailNow()
+	}
+}
+
+// generates a self-signed certificate and key as a single pem bundle
+func selfSigned(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return append(bundle, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: k})...)
+}
+
+func TestHTTPS(t *testing.T) {
+	fakeFSError = nil
+
+	// prepare false test server requiring a client certificate
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+
+		// check client certificate
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		// check token
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(getTorrentsSuccess)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// configure transmission instance with client certificate
+	client := selfSigned(t)
+	tr := Transmission{Port: port, Scheme: "https", Cert: "client.pem", Insecure: true, Retry: RetryPolicy{Attempts: 1}}
+
+	// verify insecure skip verify
+	fakeFSData = client
+	if l, err := tr.Get(); err != nil || len(l) != 10 {
+		t.Logf("error (%s) or list does not have 10 records (%+v)", err, l)
+		t.FailNow()
+	}
+
+	// verify the servers certificate is rejected without a ca
+	tr.Insecure = false
+	if _, err := tr.Get(); err == nil {
+		t.Logf("expected unknown authority error, but got: %v\n", err)
+		t.FailNow()
+	}
+
+	// verify a custom ca bundle, with the key bundled alongside the certificate
+	tr.CA = "ca.pem"
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	fakeFSData = append(client, ca...)
+	if l, err := tr.Get(); err != nil || len(l) != 10 {
+		t.Logf("error (%s) or list does not have 10 records (%+v)", err, l)
+		t.FailNow()
+	}
+
+	// verify missing client certificate is refused
+	tr.Cert = ""
+	if _, err := tr.Get(); err == nil {
+		t.Logf("expected forbidden error, but got: %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestTLSConfigFail(t *testing.T) {
+	tr := Transmission{Scheme: "https", CA: "ca.pem"}
+
+	// unreadable ca
+	fakeFSError = fsError
+	if _, err := tr.Get(); err == nil {
+		t.FailNow()
+	}
+
+	// empty ca
+	fakeFSError = nil
+	fakeFSData = []byte("not a certificate")
+	if _, err := tr.Get(); !errors.Is(err, errorBadCA) || errors.Is(err, ErrUnreachable) {
+		t.FailNow()
+	}
+
+	// bad client certificate
+	tr.CA, tr.Cert = "", "client.pem"
+	if _, err := tr.tlsConfig(); err == nil {
+		t.FailNow()
+	}
+
+	// unreadable client certificate
+	fakeFSError = fsError
+	if _, err := tr.tlsConfig(); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestContextCancelInFlight(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server that hangs until the client gives up
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// configure transmission instance
+	tr := Transmission{Port: port}
+
+	// run GetContext with a deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := tr.GetContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Logf("expected deadline exceeded promptly, but got: %v after %s\n", err, time.Since(start))
+		t.FailNow()
+	}
+}
+
+func TestContextCancelRetry(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server that always fails
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(resumeTorrentsFail)
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// configure transmission instance
+	tr := Transmission{Port: port}
+
+	// cancel while waiting to retry
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err := tr.ResumeContext(ctx)
+	if !errors.Is(err, context.Canceled) || time.Since(start) > time.Second {
+		t.Logf("expected canceled promptly, but got: %v after %s\n", err, time.Since(start))
+		t.FailNow()
+	}
+}
+
+type countingTransport struct {
+	calls int
+}
+
+func (self *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	self.calls++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(getTorrentsSuccess)
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the default client has a timeout and is reused
+	tr := Transmission{Port: port}
+	c, err := tr.httpClient()
+	if err != nil || c.Timeout != defaultTimeout {
+		t.FailNow()
+	}
+	if again, _ := tr.httpClient(); again != c {
+		t.FailNow()
+	}
+
+	// verify the default client is rebuilt when tls settings change
+	tr.Insecure = true
+	if again, _ := tr.httpClient(); again == c {
+		t.FailNow()
+	}
+
+	// verify a supplied transport is used, including the token refresh
+	rt := &countingTransport{}
+	tr.Transport = rt
+	tr.Protocol = ProtocolLegacy
+	if l, err := tr.Get(); err != nil || len(l) != 10 || rt.calls != 2 {
+		t.Logf("error (%s), list (%+v) or calls (%d) unexpected", err, l, rt.calls)
+		t.FailNow()
+	}
+
+	// verify a supplied client takes precedence
+	client := &http.Client{Transport: &countingTransport{}}
+	tr.Client = client
+	if c, _ := tr.httpClient(); c != client {
+		t.FailNow()
+	}
+	if _, err := tr.Get(); err != nil || client.Transport.(*countingTransport).calls != 1 {
+		t.FailNow()
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server listening on a unix socket
+	sock := filepath.Join(t.TempDir(), "transmission.sock")
+	listener, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+
+		// check token
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		// verify the route
+		if r.URL.Path != "/transmission/rpc" {
+			t.Fail()
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(getTorrentsSuccess)
+	}))
+	ts.Listener.Close()
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	// configure transmission instance as if read from settings.json
+	tr := Transmission{Bind: unixPrefix + sock, Uri: "/transmission/", Protocol: ProtocolLegacy}
+
+	// run Get
+	l, err := tr.Get()
+	if err != nil || len(l) != 10 {
+		t.Logf("error (%s) or list does not have 10 records (%+v)", err, l)
+		t.FailNow()
+	}
+}
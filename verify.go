@@ -0,0 +1,60 @@
+package transmission
+
+import (
+	"context"
+	"time"
+)
+
+// how often WaitVerified asks the daemon whether checking has finished
+var verifyInterval = time.Second
+
+// rechecks the local data of the supplied torrents against their pieces,
+// eg. after moving data by hand
+// @link: https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+func (self *Transmission) Verify(ids ...TorrentID) error {
+	return self.VerifyContext(context.Background(), ids...)
+}
+
+func (self *Transmission) VerifyContext(ctx context.Context, ids ...TorrentID) error {
+	return self.action(ctx, "torrent-verify", ids)
+}
+
+// asks the trackers of the supplied torrents for more peers right away,
+// eg. after a tracker outage
+func (self *Transmission) Reannounce(ids ...TorrentID) error {
+	return self.ReannounceContext(context.Background(), ids...)
+}
+
+func (self *Transmission) ReannounceContext(ctx context.Context, ids ...TorrentID) error {
+	return self.action(ctx, "torrent-reannounce", ids)
+}
+
+// polls until none of the supplied torrents are checking or waiting to
+// check, returning the resulting percentDone of each keyed by the hash of the
+// torrent; use the context to bound the wait
+func (self *Transmission) WaitVerified(ids ...TorrentID) (map[TorrentID]float64, error) {
+	return self.WaitVerifiedContext(context.Background(), ids...)
+}
+
+func (self *Transmission) WaitVerifiedContext(ctx context.Context, ids ...TorrentID) (map[TorrentID]float64, error) {
+	for {
+		torrents, err := self.torrents(ctx, ids, FieldHashString, FieldStatus, FieldPercentDone)
+		if err != nil {
+			return nil, err
+		}
+		done := make(map[TorrentID]float64, len(torrents))
+		for _, t := range torrents {
+			if t.Status.IsChecking() || t.Status == StatusCheckWait {
+				done = nil
+				break
+			}
+			done[t.TorrentID()] = t.PercentDone
+		}
+		if done != nil {
+			return done, nil
+		}
+		if err := sleep(ctx, verifyInterval); err != nil {
+			return nil, err
+		}
+	}
+}
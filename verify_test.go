@@ -0,0 +1,106 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	verifyInterval = time.Millisecond
+}
+
+func TestVerifyReannounce(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server recording each method and its ids
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		received = append(received, fmt.Sprint(c.Method, c.Arguments.Ids))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify both are sent for their selection, and nothing without one
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if tr.Verify(ID(1)) != nil || tr.Reannounce(ID(2), ID(3)) != nil || tr.Verify() != nil || tr.Reannounce() != nil {
+		t.FailNow()
+	}
+	expected := []string{"torrent-verify[1]", "torrent-reannounce[2 3]"}
+	if !reflect.DeepEqual(received, expected) {
+		t.Logf("unexpected requests: %q", received)
+		t.FailNow()
+	}
+}
+
+func TestWaitVerified(t *testing.T) {
+	t.Parallel()
+
+	// prepare false test server which finishes checking on the third poll
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		if c.Method != "torrent-get" || !reflect.DeepEqual(c.Arguments.Fields, []string{"id", "hashString", "status", "percentDone"}) {
+			t.Logf("unexpected command: %+v", c)
+			t.Fail()
+		}
+		w.WriteHeader(http.StatusOK)
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa","status":1,"percentDone":0},{"id":2,"hashString":"bb","status":2,"percentDone":0.2}]}}`))
+		case 2:
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa","status":2,"percentDone":0.1},{"id":2,"hashString":"bb","status":0,"percentDone":0.5}]}}`))
+		default:
+			w.Write([]byte(`{"result":"success","arguments":{"torrents":[{"id":1,"hashString":"aa","status":6,"percentDone":1},{"id":2,"hashString":"bb","status":0,"percentDone":0.5}]}}`))
+		}
+	}))
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the wait ends with the completion of each torrent
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	done, err := tr.WaitVerified(ID(1), ID(2))
+	if err != nil || !reflect.DeepEqual(done, map[TorrentID]float64{Hash("aa"): 1, Hash("bb"): 0.5}) || atomic.LoadInt32(&polls) != 3 {
+		t.Logf("error (%v) or unexpected result after %d polls: %v", err, polls, done)
+		t.FailNow()
+	}
+
+	// verify the context bounds the wait
+	atomic.StoreInt32(&polls, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tr.WaitVerifiedContext(ctx, ID(1)); !errors.Is(err, context.Canceled) {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
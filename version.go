@@ -0,0 +1,70 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+)
+
+// minimum rpc-version required by methods newer than the original spec,
+// which none of those wrapped so far are
+var methodVersions = map[string]int{}
+
+// the capabilities of the daemon as reported by session-get
+type Version struct {
+	RPC        int
+	RPCMinimum int
+	Semver     string
+	Daemon     string
+}
+
+// @link: https://github.com/transmission/transmission/blob/main/docs/rpc-spec.md
+func (self *Transmission) Version() (Version, error) {
+	return self.VersionContext(context.Background())
+}
+
+// asks the daemon for its versions once, and remembers the answer for the
+// lifetime of the instance
+func (self *Transmission) VersionContext(ctx context.Context) (Version, error) {
+	self.RLock()
+	v, selected := self.version, self.Protocol
+	self.RUnlock()
+	if v != nil {
+		return *v, nil
+	}
+
+	// every daemon understands the legacy session-get, unless told otherwise
+	var proto protocol = legacy{}
+	if selected == ProtocolJSONRPC {
+		proto = jsonrpc{}
+	}
+	cmd := &command{Method: "session-get", Arguments: arguments{Fields: []string{"rpc-version", "rpc-version-minimum", "rpc-version-semver", "version"}}}
+	args, err := self.exchange(ctx, proto, cmd)
+	if err != nil {
+		return Version{}, err
+	}
+	v = &Version{RPC: args.RpcVersion, RPCMinimum: args.RpcVersionMinimum, Semver: args.RpcVersionSemver, Daemon: args.Version}
+
+	self.Lock()
+	self.version = v
+	self.Unlock()
+	return *v, nil
+}
+
+// checks the daemon is new enough for the method, without asking the daemon
+// unless the method actually has a requirement
+func (self *Transmission) supports(ctx context.Context, method string) error {
+	if need, ok := methodVersions[method]; ok {
+		return self.require(ctx, method, need)
+	}
+	return nil
+}
+
+func (self *Transmission) require(ctx context.Context, feature string, need int) error {
+	v, err := self.VersionContext(ctx)
+	if err != nil {
+		return err
+	} else if v.RPC < need {
+		return fmt.Errorf("%w: %s requires rpc-version %d, but the daemon has %d", ErrUnsupported, feature, need, v.RPC)
+	}
+	return nil
+}
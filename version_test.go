@@ -0,0 +1,117 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// a method only the tests send, gated like any method newer than the spec
+const gatedMethod = "gated-get"
+
+func init() {
+	methodVersions[gatedMethod] = 15
+}
+
+// prepares a false test server advertising the supplied rpc-version, and
+// counting the session-get requests it receives
+func versionServer(t *testing.T, rpc int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("X-Transmission-Session-Id", token)
+		if r.Header.Get("X-Transmission-Session-Id") != token {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		c := &command{}
+		json.NewDecoder(r.Body).Decode(c)
+		w.WriteHeader(http.StatusOK)
+		switch c.Method {
+		case "session-get":
+			atomic.AddInt32(calls, 1)
+			w.Write([]byte(`{"result":"success","arguments":{"rpc-version":` + strconv.Itoa(rpc) + `,"rpc-version-minimum":14,"rpc-version-semver":"5.3.0","version":"4.0.5 (a6fe2a64aa)"}}`))
+		case gatedMethod:
+			w.Write([]byte(`{"result":"success","arguments":{}}`))
+		default:
+			t.Logf("unexpected method %s", c.Method)
+			t.Fail()
+		}
+	}))
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	ts := versionServer(t, 17, &calls)
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify the versions are exposed and cached
+	tr := Transmission{Port: port}
+	for i := 0; i < 2; i++ {
+		v, err := tr.Version()
+		if err != nil || v != (Version{RPC: 17, RPCMinimum: 14, Semver: "5.3.0", Daemon: "4.0.5 (a6fe2a64aa)"}) {
+			t.Logf("error (%v) or unexpected version: %+v", err, v)
+			t.FailNow()
+		}
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.FailNow()
+	}
+
+	// verify gated methods work on a new enough daemon
+	if _, err := tr.send(context.Background(), &command{Method: gatedMethod}); err != nil {
+		t.Logf("unexpected error: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestVersionUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	ts := versionServer(t, 14, &calls)
+	defer ts.Close()
+
+	// parse port off ts.URL
+	port, _ := strconv.Atoi(strings.Split(ts.URL, ":")[2])
+
+	// verify gated methods fail without being sent, even with a fixed protocol
+	tr := Transmission{Port: port, Protocol: ProtocolLegacy}
+	if _, err := tr.send(context.Background(), &command{Method: gatedMethod}); !errors.Is(err, ErrUnsupported) {
+		t.Logf("expected unsupported error, but got: %v", err)
+		t.FailNow()
+	}
+	if _, err := tr.GetFields(FieldTrackerList); !errors.Is(err, ErrUnsupported) {
+		t.Logf("expected unsupported error, but got: %v", err)
+		t.FailNow()
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.FailNow()
+	}
+}
+
+func TestVersionFail(t *testing.T) {
+	t.Parallel()
+
+	// verify negotiation failures are returned
+	tr := Transmission{Retry: RetryPolicy{Attempts: 1}}
+	if _, err := tr.Version(); !errors.Is(err, ErrUnreachable) {
+		t.Logf("expected unreachable error, but got: %v", err)
+		t.FailNow()
+	}
+	if _, err := tr.send(context.Background(), &command{Method: gatedMethod}); !errors.Is(err, ErrUnreachable) {
+		t.Logf("expected unreachable error, but got: %v", err)
+		t.FailNow()
+	}
+}